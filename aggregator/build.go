@@ -0,0 +1,246 @@
+package aggregator
+
+import (
+	"geth/contract/aggregation_router"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"math/big"
+)
+
+var permitArgs = mustArguments(
+	"address owner", "address spender", "uint256 value", "uint256 deadline",
+	"uint8 v", "bytes32 r", "bytes32 s",
+)
+
+// NewExecutorSwap packs params with the layout registered for dexID, in the
+// layout's field order, e.g. for uniswapV2: pool, tokenIn, tokenOut,
+// recipient, collectAmount, limitReturnAmount.
+func NewExecutorSwap(dexID uint64, flags uint8, params ...interface{}) (ExecutorSwap, error) {
+	layout, ok := dexLayouts[dexID]
+	if !ok {
+		return ExecutorSwap{}, errors.Errorf("aggregator: unknown dex id %d", dexID)
+	}
+	data, err := layout.args.Pack(params...)
+	if err != nil {
+		return ExecutorSwap{}, errors.WithMessage(err, "aggregator: pack "+layout.name)
+	}
+	dexOption := new(big.Int).Lsh(new(big.Int).SetUint64(dexID), 8)
+	dexOption.Or(dexOption, big.NewInt(int64(flags)))
+	return ExecutorSwap{Data: data, DexOption: dexOption}, nil
+}
+
+// EncodeExecutorData encodes the executor payload of a swap call.
+func EncodeExecutorData(desc ExecutorDescription) ([]byte, error) {
+	data, err := executorArgs.Pack(desc)
+	if err != nil {
+		return nil, errors.WithMessage(err, "aggregator: pack executor data")
+	}
+	return data, nil
+}
+
+// EncodeSimpleSwapData encodes the executor payload of a swapSimpleMode call.
+func EncodeSimpleSwapData(data SimpleSwapData) ([]byte, error) {
+	packed, err := simpleArgs.Pack(data)
+	if err != nil {
+		return nil, errors.WithMessage(err, "aggregator: pack simple swap data")
+	}
+	return packed, nil
+}
+
+// EncodePermit encodes EIP-2612 permit arguments the way the router expects
+// them in SwapDescription.Permit.
+func EncodePermit(owner, spender common.Address, value, deadline *big.Int, v uint8, r, s [32]byte) ([]byte, error) {
+	permit, err := permitArgs.Pack(owner, spender, value, deadline, v, r, s)
+	if err != nil {
+		return nil, errors.WithMessage(err, "aggregator: pack permit")
+	}
+	return permit, nil
+}
+
+// NewSwapCall builds a swap call whose executor runs sequences. The executor
+// description takes its tokens, minimum output and recipient from desc. When
+// desc has no source receivers and the source is a token, the whole amount is
+// sent to the executor. swapSimpleMode calls are built with
+// NewSimpleSwapCall.
+func NewSwapCall(method string, executor common.Address, desc aggregation_router.AggregationRouterSwapDescription, deadline *big.Int, sequences ...[]ExecutorSwap) (*SwapCall, error) {
+	if method == "swapSimpleMode" {
+		return nil, errors.New("aggregator: swapSimpleMode takes simple swap data, use NewSimpleSwapCall")
+	}
+	if method != "swap" {
+		return nil, errors.Errorf("aggregator: %s is not a swap", method)
+	}
+	if deadline == nil {
+		return nil, errors.New("aggregator: amount, minReturnAmount and deadline are required")
+	}
+	desc, err := checkDescription(desc)
+	if err != nil {
+		return nil, err
+	}
+	if len(desc.SrcReceivers) == 0 && desc.SrcToken != NativeToken {
+		desc.SrcReceivers = []common.Address{executor}
+		desc.SrcAmounts = []*big.Int{desc.Amount}
+	}
+
+	call := &SwapCall{
+		Method: method,
+		Caller: executor,
+		Desc:   desc,
+		Executor: &ExecutorDescription{
+			SwapSequences:     sequences,
+			TokenIn:           desc.SrcToken,
+			TokenOut:          desc.DstToken,
+			MinTotalAmountOut: desc.MinReturnAmount,
+			To:                desc.DstReceiver,
+			Deadline:          deadline,
+		},
+	}
+	call.ExecutorData, err = EncodeExecutorData(*call.Executor)
+	if err != nil {
+		return nil, err
+	}
+	return call, nil
+}
+
+// NewSimpleSwapCall builds a swapSimpleMode call: the router sends each of
+// data.FirstSwapAmounts to the matching first pool and the executor runs the
+// matching swap data.
+func NewSimpleSwapCall(executor common.Address, desc aggregation_router.AggregationRouterSwapDescription, data SimpleSwapData) (*SwapCall, error) {
+	if data.Deadline == nil {
+		return nil, errors.New("aggregator: amount, minReturnAmount and deadline are required")
+	}
+	if err := data.checkLengths(); err != nil {
+		return nil, err
+	}
+	desc, err := checkDescription(desc)
+	if err != nil {
+		return nil, err
+	}
+	call := &SwapCall{
+		Method: "swapSimpleMode",
+		Caller: executor,
+		Desc:   desc,
+		Simple: &data,
+	}
+	call.ExecutorData, err = EncodeSimpleSwapData(data)
+	if err != nil {
+		return nil, err
+	}
+	return call, nil
+}
+
+// checkDescription validates desc and fills in its optional fields.
+func checkDescription(desc aggregation_router.AggregationRouterSwapDescription) (aggregation_router.AggregationRouterSwapDescription, error) {
+	if desc.Amount == nil || desc.MinReturnAmount == nil {
+		return desc, errors.New("aggregator: amount, minReturnAmount and deadline are required")
+	}
+	if desc.Flags == nil {
+		desc.Flags = new(big.Int)
+	}
+	if len(desc.SrcReceivers) != len(desc.SrcAmounts) {
+		return desc, errors.New("aggregator: srcReceivers and srcAmounts differ in length")
+	}
+	return desc, nil
+}
+
+// Pack encodes the call as router calldata. ExecutorData is used as is.
+func (c *SwapCall) Pack() ([]byte, error) {
+	data, err := routerAbi.Pack(c.Method, c.Caller, c.Desc, c.ExecutorData, c.ClientData)
+	if err != nil {
+		return nil, errors.WithMessage(err, "aggregator: pack "+c.Method)
+	}
+	return data, nil
+}
+
+// Value is the ETH to send with the call: the amount when swapping from ETH.
+func (c *SwapCall) Value() *big.Int {
+	if c.Desc.SrcToken == NativeToken {
+		return new(big.Int).Set(c.Desc.Amount)
+	}
+	return new(big.Int)
+}
+
+// Rescale sets the call's input amount to amount and its minimum return to
+// minReturn, scaling what was sized on the old amount along: the source
+// amounts, the input of the first swap of each executor sequence, or the
+// first swap amounts of simple mode. ExecutorData is re-encoded. Later swaps
+// of a sequence are left as they are; they swap what the previous one
+// returned.
+func (c *SwapCall) Rescale(amount, minReturn *big.Int) error {
+	if amount == nil || minReturn == nil {
+		return errors.New("aggregator: amount and minReturn are required")
+	}
+	old := c.Desc.Amount
+	if old == nil || old.Sign() == 0 {
+		return errors.New("aggregator: cannot rescale a zero amount")
+	}
+	scale := func(x *big.Int) *big.Int {
+		return new(big.Int).Div(new(big.Int).Mul(x, amount), old)
+	}
+
+	switch {
+	case c.Executor != nil:
+		desc := *c.Executor
+		desc.SwapSequences = make([][]ExecutorSwap, len(c.Executor.SwapSequences))
+		for i, sequence := range c.Executor.SwapSequences {
+			desc.SwapSequences[i] = append([]ExecutorSwap(nil), sequence...)
+			if len(sequence) == 0 {
+				continue
+			}
+			first, err := sequence[0].scaled(scale)
+			if err != nil {
+				return err
+			}
+			desc.SwapSequences[i][0] = first
+		}
+		desc.MinTotalAmountOut = minReturn
+		data, err := EncodeExecutorData(desc)
+		if err != nil {
+			return err
+		}
+		c.Executor, c.ExecutorData = &desc, data
+	case c.Simple != nil:
+		simple := *c.Simple
+		simple.FirstSwapAmounts = make([]*big.Int, len(c.Simple.FirstSwapAmounts))
+		for i, firstAmount := range c.Simple.FirstSwapAmounts {
+			simple.FirstSwapAmounts[i] = scale(firstAmount)
+		}
+		data, err := EncodeSimpleSwapData(simple)
+		if err != nil {
+			return err
+		}
+		c.Simple, c.ExecutorData = &simple, data
+	default:
+		return errors.New("aggregator: cannot rescale undecoded executor data")
+	}
+
+	srcAmounts := make([]*big.Int, len(c.Desc.SrcAmounts))
+	for i, srcAmount := range c.Desc.SrcAmounts {
+		srcAmounts[i] = scale(srcAmount)
+	}
+	c.Desc.SrcAmounts = srcAmounts
+	c.Desc.Amount = new(big.Int).Set(amount)
+	c.Desc.MinReturnAmount = new(big.Int).Set(minReturn)
+	return nil
+}
+
+// scaled returns s with its input amount passed through scale.
+func (s ExecutorSwap) scaled(scale func(*big.Int) *big.Int) (ExecutorSwap, error) {
+	layout, ok := dexLayouts[s.DexID()]
+	if !ok || layout.amount == "" {
+		return s, errors.Errorf("aggregator: cannot rescale swaps of dex id %d", s.DexID())
+	}
+	values, err := layout.args.Unpack(s.Data)
+	if err != nil {
+		return s, errors.WithMessage(err, "aggregator: unpack "+layout.name)
+	}
+	for i, arg := range layout.args {
+		if arg.Name == layout.amount {
+			values[i] = scale(values[i].(*big.Int))
+		}
+	}
+	data, err := layout.args.Pack(values...)
+	if err != nil {
+		return s, errors.WithMessage(err, "aggregator: pack "+layout.name)
+	}
+	return ExecutorSwap{Data: data, DexOption: s.DexOption}, nil
+}
@@ -0,0 +1,346 @@
+package aggregator
+
+import (
+	"bytes"
+	"fmt"
+	"geth/contract/aggregation_router"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/pkg/errors"
+	"math/big"
+	"strings"
+)
+
+// NativeToken is the sentinel the router uses for ETH.
+var NativeToken = common.HexToAddress("0xEeeeeEeeeEeEeeEeEeEeeEEEeeeeEeeeeeeeEEeE")
+
+// SwapCall is a decoded swap or swapSimpleMode call of the aggregation router.
+type SwapCall struct {
+	Method       string
+	Caller       common.Address
+	Desc         aggregation_router.AggregationRouterSwapDescription
+	ExecutorData []byte
+	ClientData   []byte
+
+	// Executor is the decoded ExecutorData of a swap call, nil when it does
+	// not follow the executor layout. Simple is that of a swapSimpleMode
+	// call.
+	Executor *ExecutorDescription
+	Simple   *SimpleSwapData
+}
+
+// SimpleSwapData is the executor payload of swapSimpleMode: the router sends
+// FirstSwapAmounts[i] of the source token to FirstPools[i] and the executor
+// runs SwapDatas[i].
+type SimpleSwapData struct {
+	FirstPools       []common.Address
+	FirstSwapAmounts []*big.Int
+	SwapDatas        [][]byte
+	Deadline         *big.Int
+	DestTokenFeeData []byte
+}
+
+// ExecutorDescription is the payload the router hands to the executor
+// (SwapSequences are run one after the other, each as a chain of swaps).
+type ExecutorDescription struct {
+	SwapSequences     [][]ExecutorSwap
+	TokenIn           common.Address
+	TokenOut          common.Address
+	MinTotalAmountOut *big.Int
+	To                common.Address
+	Deadline          *big.Int
+	DestTokenFeeData  []byte
+}
+
+// ExecutorSwap is a single hop. DexOption packs the dex id in the bits above
+// the lowest byte and per-swap flags in the lowest byte.
+type ExecutorSwap struct {
+	Data      []byte
+	DexOption *big.Int
+}
+
+// InnerCall is an ExecutorSwap decoded with the layout of its dex.
+type InnerCall struct {
+	Dex    string
+	Flags  uint8
+	Params []Param
+}
+
+type Param struct {
+	Name  string
+	Value interface{}
+}
+
+type dexLayout struct {
+	name string
+	args abi.Arguments
+	// amount is the field holding the input amount of a hop, empty if
+	// unknown.
+	amount string
+}
+
+var (
+	routerAbi abi.ABI
+
+	executorArgs abi.Arguments
+	simpleArgs   abi.Arguments
+
+	// dexLayouts maps dex ids to the layout of their swap data. Ids and
+	// layouts are the ones observed in router calldata.
+	dexLayouts = map[uint64]dexLayout{
+		2: {"curve", mustArguments(
+			"address pool", "address tokenFrom", "address tokenTo",
+			"int128 tokenIndexFrom", "int128 tokenIndexTo",
+			"uint256 dx", "uint256 minDy", "bool usePoolUnderlying", "bool useTriCrypto",
+		), "dx"},
+		3: {"uniswapV2", mustArguments(
+			"address pool", "address tokenIn", "address tokenOut", "address recipient",
+			"uint256 collectAmount", "uint256 limitReturnAmount",
+		), "collectAmount"},
+		// Uniswap V3 and KyberSwap Elastic pools share a layout, told apart
+		// by isUniV3.
+		4: {"uniswapV3", mustArguments(
+			"address recipient", "address pool", "address tokenIn", "address tokenOut",
+			"uint256 swapAmount", "uint256 limitReturnAmount", "uint160 sqrtPriceLimitX96", "bool isUniV3",
+		), "swapAmount"},
+		6: {"balancerV2", mustArguments(
+			"address vault", "bytes32 poolId", "address assetIn", "address assetOut",
+			"uint256 amount", "uint256 limit",
+		), "amount"},
+	}
+)
+
+func init() {
+	var err error
+	routerAbi, err = abi.JSON(strings.NewReader(aggregation_router.ContractMetaData.ABI))
+	if err != nil {
+		panic(err)
+	}
+
+	executorDesc, err := abi.NewType("tuple", "", []abi.ArgumentMarshaling{
+		{Name: "swapSequences", Type: "tuple[][]", Components: []abi.ArgumentMarshaling{
+			{Name: "data", Type: "bytes"},
+			{Name: "dexOption", Type: "uint256"},
+		}},
+		{Name: "tokenIn", Type: "address"},
+		{Name: "tokenOut", Type: "address"},
+		{Name: "minTotalAmountOut", Type: "uint256"},
+		{Name: "to", Type: "address"},
+		{Name: "deadline", Type: "uint256"},
+		{Name: "destTokenFeeData", Type: "bytes"},
+	})
+	if err != nil {
+		panic(err)
+	}
+	executorArgs = abi.Arguments{{Name: "desc", Type: executorDesc}}
+
+	simpleData, err := abi.NewType("tuple", "", []abi.ArgumentMarshaling{
+		{Name: "firstPools", Type: "address[]"},
+		{Name: "firstSwapAmounts", Type: "uint256[]"},
+		{Name: "swapDatas", Type: "bytes[]"},
+		{Name: "deadline", Type: "uint256"},
+		{Name: "destTokenFeeData", Type: "bytes"},
+	})
+	if err != nil {
+		panic(err)
+	}
+	simpleArgs = abi.Arguments{{Name: "data", Type: simpleData}}
+}
+
+// mustArguments builds flat ABI arguments from "type name" pairs.
+func mustArguments(fields ...string) abi.Arguments {
+	args := make(abi.Arguments, 0, len(fields))
+	for _, field := range fields {
+		parts := strings.Fields(field)
+		typ, err := abi.NewType(parts[0], "", nil)
+		if err != nil {
+			panic(err)
+		}
+		args = append(args, abi.Argument{Name: parts[1], Type: typ})
+	}
+	return args
+}
+
+// RegisterDex adds or replaces the swap data layout of a dex id. Fields are
+// "type name" pairs of static ABI types, e.g. "address pool". Its swaps
+// cannot be rescaled as the input amount field is unknown. It is meant to be
+// called during initialisation.
+func RegisterDex(id uint64, name string, fields ...string) {
+	dexLayouts[id] = dexLayout{name: name, args: mustArguments(fields...)}
+}
+
+// DecodeSwapCall decodes swap/swapSimpleMode calldata and, where possible, the
+// executor data it carries.
+func DecodeSwapCall(calldata []byte) (*SwapCall, error) {
+	if len(calldata) < 4 {
+		return nil, errors.New("aggregator: calldata too short")
+	}
+	method, err := routerAbi.MethodById(calldata[:4])
+	if err != nil {
+		return nil, errors.WithMessage(err, "aggregator: unknown router method")
+	}
+	if method.Name != "swap" && method.Name != "swapSimpleMode" {
+		return nil, errors.Errorf("aggregator: %s is not a swap", method.Name)
+	}
+	params, err := method.Inputs.Unpack(calldata[4:])
+	if err != nil {
+		return nil, errors.WithMessage(err, "aggregator: unpack "+method.Name)
+	}
+
+	call := &SwapCall{
+		Method:       method.Name,
+		Caller:       params[0].(common.Address),
+		Desc:         *abi.ConvertType(params[1], new(aggregation_router.AggregationRouterSwapDescription)).(*aggregation_router.AggregationRouterSwapDescription),
+		ExecutorData: params[2].([]byte),
+		ClientData:   params[3].([]byte),
+	}
+	if call.Method == "swapSimpleMode" {
+		call.Simple, _ = DecodeSimpleSwapData(call.ExecutorData)
+	} else {
+		call.Executor, _ = DecodeExecutorData(call.ExecutorData)
+	}
+	return call, nil
+}
+
+// DecodeExecutorData decodes the executor payload of a swap call.
+func DecodeExecutorData(data []byte) (*ExecutorDescription, error) {
+	values, err := executorArgs.Unpack(data)
+	if err != nil {
+		return nil, errors.WithMessage(err, "aggregator: unpack executor data")
+	}
+	return abi.ConvertType(values[0], new(ExecutorDescription)).(*ExecutorDescription), nil
+}
+
+// DecodeSimpleSwapData decodes the executor payload of a swapSimpleMode call.
+func DecodeSimpleSwapData(data []byte) (*SimpleSwapData, error) {
+	values, err := simpleArgs.Unpack(data)
+	if err != nil {
+		return nil, errors.WithMessage(err, "aggregator: unpack simple swap data")
+	}
+	simple := abi.ConvertType(values[0], new(SimpleSwapData)).(*SimpleSwapData)
+	if err := simple.checkLengths(); err != nil {
+		return nil, err
+	}
+	return simple, nil
+}
+
+// checkLengths checks that each first pool has an amount and swap data.
+func (d *SimpleSwapData) checkLengths() error {
+	if len(d.FirstPools) != len(d.FirstSwapAmounts) || len(d.FirstPools) != len(d.SwapDatas) {
+		return errors.New("aggregator: firstPools, firstSwapAmounts and swapDatas differ in length")
+	}
+	return nil
+}
+
+func (s ExecutorSwap) DexID() uint64 {
+	return new(big.Int).Rsh(s.DexOption, 8).Uint64()
+}
+
+func (s ExecutorSwap) Flags() uint8 {
+	return uint8(s.DexOption.Uint64())
+}
+
+// Decode decodes the swap data with the layout registered for its dex.
+func (s ExecutorSwap) Decode() (*InnerCall, error) {
+	layout, ok := dexLayouts[s.DexID()]
+	if !ok {
+		return nil, errors.Errorf("aggregator: unknown dex id %d", s.DexID())
+	}
+	values, err := layout.args.Unpack(s.Data)
+	if err != nil {
+		return nil, errors.WithMessage(err, "aggregator: unpack "+layout.name)
+	}
+	call := &InnerCall{
+		Dex:    layout.name,
+		Flags:  s.Flags(),
+		Params: make([]Param, 0, len(values)),
+	}
+	for i, value := range values {
+		call.Params = append(call.Params, Param{Name: layout.args[i].Name, Value: value})
+	}
+	return call, nil
+}
+
+// String renders the call as an indented, human-readable tree.
+func (c *SwapCall) String() string {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "%s\n", c.Method)
+	fmt.Fprintf(&b, "  caller          %s\n", c.Caller.Hex())
+	fmt.Fprintf(&b, "  srcToken        %s\n", tokenString(c.Desc.SrcToken))
+	fmt.Fprintf(&b, "  dstToken        %s\n", tokenString(c.Desc.DstToken))
+	// Calldata may carry these with different lengths.
+	for i, receiver := range c.Desc.SrcReceivers {
+		fmt.Fprintf(&b, "  srcReceiver[%d]  %s\n", i, receiver.Hex())
+	}
+	for i, srcAmount := range c.Desc.SrcAmounts {
+		fmt.Fprintf(&b, "  srcAmount[%d]    %s\n", i, srcAmount)
+	}
+	fmt.Fprintf(&b, "  dstReceiver     %s\n", c.Desc.DstReceiver.Hex())
+	fmt.Fprintf(&b, "  amount          %s\n", c.Desc.Amount)
+	fmt.Fprintf(&b, "  minReturnAmount %s\n", c.Desc.MinReturnAmount)
+	fmt.Fprintf(&b, "  flags           %s\n", c.Desc.Flags)
+	fmt.Fprintf(&b, "  permit          %s\n", hexutil.Encode(c.Desc.Permit))
+	fmt.Fprintf(&b, "  clientData      %s\n", hexutil.Encode(c.ClientData))
+
+	if c.Simple != nil {
+		d := c.Simple
+		fmt.Fprintf(&b, "  simple\n")
+		fmt.Fprintf(&b, "    deadline          %s\n", d.Deadline)
+		fmt.Fprintf(&b, "    destTokenFeeData  %s\n", hexutil.Encode(d.DestTokenFeeData))
+		for i, pool := range d.FirstPools {
+			if i >= len(d.FirstSwapAmounts) || i >= len(d.SwapDatas) {
+				fmt.Fprintf(&b, "    firstPool[%d]      %s\n", i, pool.Hex())
+				continue
+			}
+			fmt.Fprintf(&b, "    firstPool[%d]      %s amount %s\n", i, pool.Hex(), d.FirstSwapAmounts[i])
+			fmt.Fprintf(&b, "      swapData        %s\n", hexutil.Encode(d.SwapDatas[i]))
+		}
+		return b.String()
+	}
+	if c.Executor == nil {
+		fmt.Fprintf(&b, "  executorData    %s\n", hexutil.Encode(c.ExecutorData))
+		return b.String()
+	}
+	e := c.Executor
+	fmt.Fprintf(&b, "  executor\n")
+	fmt.Fprintf(&b, "    tokenIn           %s\n", tokenString(e.TokenIn))
+	fmt.Fprintf(&b, "    tokenOut          %s\n", tokenString(e.TokenOut))
+	fmt.Fprintf(&b, "    minTotalAmountOut %s\n", e.MinTotalAmountOut)
+	fmt.Fprintf(&b, "    to                %s\n", e.To.Hex())
+	fmt.Fprintf(&b, "    deadline          %s\n", e.Deadline)
+	fmt.Fprintf(&b, "    destTokenFeeData  %s\n", hexutil.Encode(e.DestTokenFeeData))
+	for i, sequence := range e.SwapSequences {
+		fmt.Fprintf(&b, "    sequence %d\n", i)
+		for j, swap := range sequence {
+			inner, err := swap.Decode()
+			if err != nil {
+				fmt.Fprintf(&b, "      swap %d dex %d flags %d data %s\n", j, swap.DexID(), swap.Flags(), hexutil.Encode(swap.Data))
+				continue
+			}
+			fmt.Fprintf(&b, "      swap %d %s flags %d\n", j, inner.Dex, inner.Flags)
+			for _, p := range inner.Params {
+				fmt.Fprintf(&b, "        %-18s %s\n", p.Name, valueString(p.Value))
+			}
+		}
+	}
+	return b.String()
+}
+
+func tokenString(token common.Address) string {
+	if token == NativeToken {
+		return token.Hex() + " (ETH)"
+	}
+	return token.Hex()
+}
+
+func valueString(v interface{}) string {
+	switch v := v.(type) {
+	case common.Address:
+		return v.Hex()
+	case [32]byte:
+		return hexutil.Encode(v[:])
+	default:
+		return fmt.Sprint(v)
+	}
+}
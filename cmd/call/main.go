@@ -3,20 +3,19 @@ package main
 import (
 	"bytes"
 	"context"
-	"encoding/json"
 	"fmt"
+	"geth/aggregator"
 	"geth/contract/simswap"
-	"github.com/ethereum/go-ethereum"
+	"geth/rpccache"
+	"geth/rpcpool"
+	"geth/simulation"
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
-	"github.com/ethereum/go-ethereum/ethclient"
-	"github.com/ethereum/go-ethereum/rpc"
-	solsha3 "github.com/miguelmota/go-solidity-sha3"
 	"github.com/pkg/errors"
-	"io/ioutil"
 	"math/big"
-	"net/http"
+	"os"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -25,187 +24,640 @@ const (
 	expBase = 10
 )
 
-type Account struct {
-	Nonce     string            `json:"nonce,omitempty"`
-	Balance   string            `json:"balance,omitempty"`
-	Code      string            `json:"code,omitempty"`
-	State     map[string]string `json:"state,omitempty"`
-	StateDiff map[string]string `json:"stateDiff,omitempty"`
-}
-
-type OverrideAccounts map[common.Address]Account
-
-type roundTripperExt struct {
-	c          *http.Client
-	appendData json.RawMessage
-}
-
-type reqMessage struct {
-	JSONRPC string            `json:"jsonrpc"`
-	ID      int               `json:"id"`
-	Method  string            `json:"method"`
-	Params  []json.RawMessage `json:"params"`
-}
-
 var (
 	SimSwapAddress = common.HexToAddress("0x1111111111111111111111111111111111111100")
 	MyWallet       = common.HexToAddress("0x198c08797DD4341f738EC18FCD05d64f645B8228")
 	Router         = common.HexToAddress("0x00555513Acf282B42882420E5e5bA87b44D8fA6E")
 
+	UniswapV2Router = common.HexToAddress("0x7a250d5630B4cF539739dF2C5dAcb4c659F2488D")
+
 	DAIContract = common.HexToAddress("0x6b175474e89094c44da98b954eedeac495271d0f")
 	KNCContract = common.HexToAddress("0xdeFA4e8a7bcBA345F687a2f1456F5Edd9CE97202")
 
-	DAIBalanceOfSlot = "2"
-	DAIAllowanceSlot = "3"
-	KNCAllowanceSlot = "102"
+	// Endpoints are the mainnet nodes calls are spread over. Each call only
+	// goes to endpoints supporting what it needs.
+	Endpoints = []rpcpool.Endpoint{
+		{URL: "https://mainnet.infura.io/v3/c8a0f577c41240ab90d542d4c1f9f1ba", Weight: 2, Overrides: true, Archive: true, MaxInFlight: 32, RateLimit: 50},
+		{URL: "https://mainnet.infura.io/v3/3d85e3bded764846bc25e1ca36f73b91", Weight: 1, Overrides: true, Archive: true, MaxInFlight: 32, RateLimit: 50},
+		{URL: "https://proxy.kyberengineering.io/ethereum", Weight: 1, Overrides: true},
+		{URL: "http://localhost:8545/", Weight: 1, Overrides: true, Trace: true},
+	}
 
-	InputData = "0xabcffc2600000000000000000000000041684b361557e9282e0373ca51260d9331e518c90000000000000000000000000000000000000000000000000000000000000080000000000000000000000000000000000000000000000000000000000000024000000000000000000000000000000000000000000000000000000000000008000000000000000000000000006b175474e89094c44da98b954eedeac495271d0f000000000000000000000000defa4e8a7bcba345f687a2f1456f5edd9ce9720200000000000000000000000000000000000000000000000000000000000001200000000000000000000000000000000000000000000000000000000000000160000000000000000000000000198c08797dd4341f738ec18fcd05d64f645b822800000000000000000000000000000000000000000000003635c9adc5dea00000000000000000000000000000000000000000000000000020a1691d08bc8f7727000000000000000000000000000000000000000000000000000000000000000400000000000000000000000000000000000000000000000000000000000001a0000000000000000000000000000000000000000000000000000000000000000100000000000000000000000041684b361557e9282e0373ca51260d9331e518c9000000000000000000000000000000000000000000000000000000000000000100000000000000000000000000000000000000000000003635c9adc5dea00000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000005a0000000000000000000000000000000000000000000000000000000000000002000000000000000000000000000000000000000000000000000000000000000e00000000000000000000000006b175474e89094c44da98b954eedeac495271d0f000000000000000000000000defa4e8a7bcba345f687a2f1456f5edd9ce97202000000000000000000000000000000000000000000000020a1691d08bc8f7727000000000000000000000000198c08797dd4341f738ec18fcd05d64f645b82280000000000000000000000000000000000000000000000000000000062fcb79500000000000000000000000000000000000000000000000000000000000005600000000000000000000000000000000000000000000000000000000000000001000000000000000000000000000000000000000000000000000000000000002000000000000000000000000000000000000000000000000000000000000000030000000000000000000000000000000000000000000000000000000000000060000000000000000000000000000000000000000000000000000000000000018000000000000000000000000000000000000000000000000000000000000003000000000000000000000000000000000000000000000000000000000000000040000000000000000000000000000000000000000000000000000000000000060100000000000000000000000000000000000000000000000000000000000000c0000000000000000000000000ba12222222228d8ba445958a75a0704d566bf2c806df3b2bbb68adc8b0e302443692037ed9f91b420000000000000000000000630000000000000000000000006b175474e89094c44da98b954eedeac495271d0f000000000000000000000000dac17f958d2ee523a2206206994597c13d831ec700000000000000000000000000000000000000000000003635c9adc5dea000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000004000000000000000000000000000000000000000000000000000000000000002010000000000000000000000000000000000000000000000000000000000000120000000000000000000000000d51a44d3fae010294c616388b506acda1bfaae46000000000000000000000000dac17f958d2ee523a2206206994597c13d831ec7000000000000000000000000c02aaa39b223fe8d0a0e5c4f27ead9083c756cc200000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000002000000000000000000000000000000000000000000000000000000003b976e460000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000010000000000000000000000000000000000000000000000000000000000000040000000000000000000000000000000000000000000000000000000000000030000000000000000000000000000000000000000000000000000000000000000c000000000000000000000000061639d6ec06c13a96b5eb9560b359d7c648c7759000000000000000000000000c02aaa39b223fe8d0a0e5c4f27ead9083c756cc2000000000000000000000000defa4e8a7bcba345f687a2f1456f5edd9ce97202000000000000000000000000198c08797dd4341f738ec18fcd05d64f645b8228000000000000000000000000000000000000000000000000000000000000000100000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000004000000000000000000000000000000000000000000000000000000000000000200000000000000000000000000000000000000000000000000000000000000000"
-)
+	// BenchWorkers is the number of concurrent simulations of the bench mode,
+	// each bounded by BenchTimeout.
+	BenchWorkers = 32
+	BenchTimeout = 10 * time.Second
 
-func InitCommonContract() *OverrideAccounts {
-	indexDaiBalanceOf := getIndexBalanceOf(MyWallet.String(), DAIBalanceOfSlot)
-	fmt.Println("indexDaiBalanceOf", indexDaiBalanceOf)
+	// SweepAmounts is the default grid of the sweep mode, in whole source
+	// tokens.
+	SweepAmounts = []float64{1, 10, 100, 1e3, 1e4, 1e5, 1e6}
 
-	indexDaiAllowance := getIndexAllowance(MyWallet.String(), SimSwapAddress.String(), DAIAllowanceSlot)
-	fmt.Println("indexDaiAllowance", indexDaiAllowance)
+	// CacheSize is how many node responses are kept in memory; CacheDir, if
+	// set, also keeps them on disk across runs.
+	CacheSize = 10000
+	CacheDir  = ""
 
-	indexKncAllowance := getIndexAllowance(MyWallet.String(), SimSwapAddress.String(), KNCAllowanceSlot)
-	fmt.Println("indexKncAllowance", indexKncAllowance)
+	// ChainID is the chain of Endpoints; it selects the registry entries.
+	ChainID uint64 = 1
+	// RegistryPath is the token storage-layout registry, relative to the
+	// repository root.
+	RegistryPath = "tokens.json"
 
-	fakeBalance := "0x" + toHashString("0x3635C9ADC5DEA00000")
-	fmt.Println("fakeBalance", fakeBalance)
+	InputData = "0xabcffc2600000000000000000000000041684b361557e9282e0373ca51260d9331e518c90000000000000000000000000000000000000000000000000000000000000080000000000000000000000000000000000000000000000000000000000000024000000000000000000000000000000000000000000000000000000000000008000000000000000000000000006b175474e89094c44da98b954eedeac495271d0f000000000000000000000000defa4e8a7bcba345f687a2f1456f5edd9ce9720200000000000000000000000000000000000000000000000000000000000001200000000000000000000000000000000000000000000000000000000000000160000000000000000000000000198c08797dd4341f738ec18fcd05d64f645b822800000000000000000000000000000000000000000000003635c9adc5dea00000000000000000000000000000000000000000000000000020a1691d08bc8f7727000000000000000000000000000000000000000000000000000000000000000400000000000000000000000000000000000000000000000000000000000001a0000000000000000000000000000000000000000000000000000000000000000100000000000000000000000041684b361557e9282e0373ca51260d9331e518c9000000000000000000000000000000000000000000000000000000000000000100000000000000000000000000000000000000000000003635c9adc5dea00000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000005a0000000000000000000000000000000000000000000000000000000000000002000000000000000000000000000000000000000000000000000000000000000e00000000000000000000000006b175474e89094c44da98b954eedeac495271d0f000000000000000000000000defa4e8a7bcba345f687a2f1456f5edd9ce97202000000000000000000000000000000000000000000000020a1691d08bc8f7727000000000000000000000000198c08797dd4341f738ec18fcd05d64f645b82280000000000000000000000000000000000000000000000000000000062fcb79500000000000000000000000000000000000000000000000000000000000005600000000000000000000000000000000000000000000000000000000000000001000000000000000000000000000000000000000000000000000000000000002000000000000000000000000000000000000000000000000000000000000000030000000000000000000000000000000000000000000000000000000000000060000000000000000000000000000000000000000000000000000000000000018000000000000000000000000000000000000000000000000000000000000003000000000000000000000000000000000000000000000000000000000000000040000000000000000000000000000000000000000000000000000000000000060100000000000000000000000000000000000000000000000000000000000000c0000000000000000000000000ba12222222228d8ba445958a75a0704d566bf2c806df3b2bbb68adc8b0e302443692037ed9f91b420000000000000000000000630000000000000000000000006b175474e89094c44da98b954eedeac495271d0f000000000000000000000000dac17f958d2ee523a2206206994597c13d831ec700000000000000000000000000000000000000000000003635c9adc5dea000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000004000000000000000000000000000000000000000000000000000000000000002010000000000000000000000000000000000000000000000000000000000000120000000000000000000000000d51a44d3fae010294c616388b506acda1bfaae46000000000000000000000000dac17f958d2ee523a2206206994597c13d831ec7000000000000000000000000c02aaa39b223fe8d0a0e5c4f27ead9083c756cc200000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000002000000000000000000000000000000000000000000000000000000003b976e460000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000010000000000000000000000000000000000000000000000000000000000000040000000000000000000000000000000000000000000000000000000000000030000000000000000000000000000000000000000000000000000000000000000c000000000000000000000000061639d6ec06c13a96b5eb9560b359d7c648c7759000000000000000000000000c02aaa39b223fe8d0a0e5c4f27ead9083c756cc2000000000000000000000000defa4e8a7bcba345f687a2f1456f5edd9ce97202000000000000000000000000198c08797dd4341f738ec18fcd05d64f645b8228000000000000000000000000000000000000000000000000000000000000000100000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000004000000000000000000000000000000000000000000000000000000000000000200000000000000000000000000000000000000000000000000000000000000000"
+)
 
-	fakeAllowance := "0xFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFF"
-	fmt.Println("fakeAllowance", fakeAllowance)
+func InitCommonContract() *simulation.OverrideAccounts {
+	registry, err := simulation.LoadRegistry(RegistryPath)
+	if err != nil {
+		panic(err)
+	}
+	fakeBalance, _ := new(big.Int).SetString("3635C9ADC5DEA00000", 16)
+	fakeAllowance := abi.MaxUint256
 
-	return &OverrideAccounts{
+	overrides := simulation.OverrideAccounts{
 		SimSwapAddress: {
 			Nonce: "0x10",
-			Code:  "0x60806040526004361061003f5760003560e01c806321c4f09f1461004457806368116177146100745780637e5465ba146100a457806396d27420146100e1575b600080fd5b61005e600480360381019061005991906107cc565b610112565b60405161006b9190610825565b60405180910390f35b61008e60048036038101906100899190610840565b6101a3565b60405161009b9190610825565b60405180910390f35b3480156100b057600080fd5b506100cb60048036038101906100c691906107cc565b610231565b6040516100d89190610825565b60405180910390f35b6100fb60048036038101906100f691906108d2565b6102e1565b60405161010992919061095a565b60405180910390f35b60008083905060008173ffffffffffffffffffffffffffffffffffffffff1663dd62ed3e33866040518363ffffffff1660e01b8152600401610155929190610992565b602060405180830381865afa158015610172573d6000803e3d6000fd5b505050506040513d601f19601f8201168201806040525081019061019691906109e7565b9050809250505092915050565b60008082905060008173ffffffffffffffffffffffffffffffffffffffff166370a08231336040518263ffffffff1660e01b81526004016101e49190610a14565b602060405180830381865afa158015610201573d6000803e3d6000fd5b505050506040513d601f19601f8201168201806040525081019061022591906109e7565b90508092505050919050565b6000808390508073ffffffffffffffffffffffffffffffffffffffff1663095ea7b3847f80000000000000000000000000000000000000000000000000000000000000006040518363ffffffff1660e01b8152600401610292929190610a74565b6020604051808303816000875af11580156102b1573d6000803e3d6000fd5b505050506040513d601f19601f820116820180604052508101906102d59190610ad5565b50600091505092915050565b6000806000879050600087905060008273ffffffffffffffffffffffffffffffffffffffff166370a08231336040518263ffffffff1660e01b81526004016103299190610a14565b602060405180830381865afa158015610346573d6000803e3d6000fd5b505050506040513d601f19601f8201168201806040525081019061036a91906109e7565b905060008273ffffffffffffffffffffffffffffffffffffffff166370a08231336040518263ffffffff1660e01b81526004016103a79190610a14565b602060405180830381865afa1580156103c4573d6000803e3d6000fd5b505050506040513d601f19601f820116820180604052508101906103e891906109e7565b90503073ffffffffffffffffffffffffffffffffffffffff16637e5465ba8c8b6040518363ffffffff1660e01b8152600401610425929190610992565b6020604051808303816000875af1158015610444573d6000803e3d6000fd5b505050506040513d601f19601f8201168201806040525081019061046891906109e7565b506104b78989898080601f016020809104026020016040519081016040528093929190818152602001838380828437600081840152601f19601f820116905080830192505050505050506105e0565b5060008473ffffffffffffffffffffffffffffffffffffffff166370a08231336040518263ffffffff1660e01b81526004016104f39190610a14565b602060405180830381865afa158015610510573d6000803e3d6000fd5b505050506040513d601f19601f8201168201806040525081019061053491906109e7565b905060008473ffffffffffffffffffffffffffffffffffffffff166370a08231336040518263ffffffff1660e01b81526004016105719190610a14565b602060405180830381865afa15801561058e573d6000803e3d6000fd5b505050506040513d601f19601f820116820180604052508101906105b291906109e7565b905081846105c09190610b31565b975082816105ce9190610b31565b96505050505050509550959350505050565b60606106058383604051806060016040528060278152602001610d116027913961060d565b905092915050565b6060610618846106da565b610657576040517f08c379a000000000000000000000000000000000000000000000000000000000815260040161064e90610be8565b60405180910390fd5b6000808573ffffffffffffffffffffffffffffffffffffffff168560405161067f9190610c82565b600060405180830381855af49150503d80600081146106ba576040519150601f19603f3d011682016040523d82523d6000602084013e6106bf565b606091505b50915091506106cf8282866106fd565b925050509392505050565b6000808273ffffffffffffffffffffffffffffffffffffffff163b119050919050565b6060831561070d5782905061075d565b6000835111156107205782518084602001fd5b816040517f08c379a00000000000000000000000000000000000000000000000000000000081526004016107549190610cee565b60405180910390fd5b9392505050565b600080fd5b600080fd5b600073ffffffffffffffffffffffffffffffffffffffff82169050919050565b60006107998261076e565b9050919050565b6107a98161078e565b81146107b457600080fd5b50565b6000813590506107c6816107a0565b92915050565b600080604083850312156107e3576107e2610764565b5b60006107f1858286016107b7565b9250506020610802858286016107b7565b9150509250929050565b6000819050919050565b61081f8161080c565b82525050565b600060208201905061083a6000830184610816565b92915050565b60006020828403121561085657610855610764565b5b6000610864848285016107b7565b91505092915050565b600080fd5b600080fd5b600080fd5b60008083601f8401126108925761089161086d565b5b8235905067ffffffffffffffff8111156108af576108ae610872565b5b6020830191508360018202830111156108cb576108ca610877565b5b9250929050565b6000806000806000608086880312156108ee576108ed610764565b5b60006108fc888289016107b7565b955050602061090d888289016107b7565b945050604061091e888289016107b7565b935050606086013567ffffffffffffffff81111561093f5761093e610769565b5b61094b8882890161087c565b92509250509295509295909350565b600060408201905061096f6000830185610816565b61097c6020830184610816565b9392505050565b61098c8161078e565b82525050565b60006040820190506109a76000830185610983565b6109b46020830184610983565b9392505050565b6109c48161080c565b81146109cf57600080fd5b50565b6000815190506109e1816109bb565b92915050565b6000602082840312156109fd576109fc610764565b5b6000610a0b848285016109d2565b91505092915050565b6000602082019050610a296000830184610983565b92915050565b6000819050919050565b6000819050919050565b6000610a5e610a59610a5484610a2f565b610a39565b61080c565b9050919050565b610a6e81610a43565b82525050565b6000604082019050610a896000830185610983565b610a966020830184610a65565b9392505050565b60008115159050919050565b610ab281610a9d565b8114610abd57600080fd5b50565b600081519050610acf81610aa9565b92915050565b600060208284031215610aeb57610aea610764565b5b6000610af984828501610ac0565b91505092915050565b7f4e487b7100000000000000000000000000000000000000000000000000000000600052601160045260246000fd5b6000610b3c8261080c565b9150610b478361080c565b925082821015610b5a57610b59610b02565b5b828203905092915050565b600082825260208201905092915050565b7f416464726573733a2064656c65676174652063616c6c20746f206e6f6e2d636f60008201527f6e74726163740000000000000000000000000000000000000000000000000000602082015250565b6000610bd2602683610b65565b9150610bdd82610b76565b604082019050919050565b60006020820190508181036000830152610c0181610bc5565b9050919050565b600081519050919050565b600081905092915050565b60005b83811015610c3c578082015181840152602081019050610c21565b83811115610c4b576000848401525b50505050565b6000610c5c82610c08565b610c668185610c13565b9350610c76818560208601610c1e565b80840191505092915050565b6000610c8e8284610c51565b915081905092915050565b600081519050919050565b6000601f19601f8301169050919050565b6000610cc082610c99565b610cca8185610b65565b9350610cda818560208601610c1e565b610ce381610ca4565b840191505092915050565b60006020820190508181036000830152610d088184610cb5565b90509291505056fe416464726573733a206c6f772d6c6576656c2064656c65676174652063616c6c206661696c6564a26469706673582212208a670ec4dc4c15570f950427558a542a07e394ffe618b2bae4e15e7d4a2b177864736f6c634300080f0033",
+			Code:  simswap.RuntimeCode,
 		},
 		MyWallet: {
 			Balance: "0x8ac7230489e80000",
 		},
-		DAIContract: {
-			StateDiff: map[string]string{
-				indexDaiBalanceOf.String(): fakeBalance,
-				indexDaiAllowance.String(): fakeAllowance,
-			},
-		},
-		KNCContract: {
-			StateDiff: map[string]string{
-				indexKncAllowance.String(): fakeAllowance,
-			},
-		},
 	}
+	for _, extra := range []simulation.OverrideAccounts{
+		mustOverrides(registry.FundOverrides(ChainID, DAIContract, MyWallet, fakeBalance)),
+		mustOverrides(registry.ApproveOverrides(ChainID, DAIContract, MyWallet, SimSwapAddress, fakeAllowance)),
+		mustOverrides(registry.ApproveOverrides(ChainID, KNCContract, MyWallet, SimSwapAddress, fakeAllowance)),
+	} {
+		overrides = overrides.Merge(extra)
+	}
+	return &overrides
+}
+
+func mustOverrides(overrides simulation.OverrideAccounts, err error) simulation.OverrideAccounts {
+	if err != nil {
+		panic(err)
+	}
+	return overrides
 }
 
 func main() {
 	startTime := time.Now()
 
+	if len(os.Args) > 1 && os.Args[1] == "decode" {
+		calldata := InputData
+		if len(os.Args) > 2 {
+			calldata = os.Args[2]
+		}
+		DecodeSwap(calldata)
+		return
+	}
+
 	commonContract := InitCommonContract()
 	// https://etherscan.io/tx/0x606e8c8084855d3fb20cb1c69f520d0a1feae6c35a9d3659a9cda8a1cf53e9e2#eventlog
-	//rawurl := "https://proxy.kyberengineering.io/ethereum" // "http://localhost:8545/" //  "https://mainnet.infura.io/v3/3d85e3bded764846bc25e1ca36f73b91" // "https://proxy.kyberengineering.io/ethereum"
-	rawurl := "https://mainnet.infura.io/v3/c8a0f577c41240ab90d542d4c1f9f1ba"
+	pool, err := rpcpool.Dial(context.Background(), Endpoints)
+	if err != nil {
+		panic(err)
+	}
+	defer pool.Close()
+	var disk rpccache.Backend
+	if CacheDir != "" {
+		if disk, err = rpccache.NewDisk(CacheDir); err != nil {
+			panic(err)
+		}
+	}
+	cache := rpccache.New(pool, CacheSize, disk)
+	defer func() { fmt.Fprintln(os.Stderr, "cache", cache.Metrics()) }()
+	// Every step of a run reads the same block, so results can be cached.
+	session, err := simulation.NewSession(context.Background(), cache)
+	if err != nil {
+		panic(err)
+	}
+	// Cache hits do not reach the node, so a reorg of the pinned block only
+	// shows when asked for.
+	defer func() {
+		if err := session.CheckReorg(context.Background()); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+		}
+	}()
 
-	// Generate EncodedSwapData
-	simClient, err := NewClient(rawurl, SimSwapAddress, commonContract)
+	if len(os.Args) > 2 && os.Args[1] == "raw" {
+		SimulateRawTx(session, os.Args[2])
+		fmt.Println("Execution time: ", time.Now().Sub(startTime))
+		return
+	}
+	if len(os.Args) > 2 && os.Args[1] == "replay" {
+		ReplayTx(session, common.HexToHash(os.Args[2]))
+		fmt.Println("Execution time: ", time.Now().Sub(startTime))
+		return
+	}
+	if len(os.Args) > 3 && os.Args[1] == "token" {
+		AnalyzeToken(session, common.HexToAddress(os.Args[2]), os.Args[3])
+		fmt.Println("Execution time: ", time.Now().Sub(startTime))
+		return
+	}
+	if len(os.Args) > 2 && os.Args[1] == "findslots" {
+		FindSlots(session, common.HexToAddress(os.Args[2]))
+		fmt.Println("Execution time: ", time.Now().Sub(startTime))
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "slots" {
+		VerifySlots(session)
+		fmt.Println("Execution time: ", time.Now().Sub(startTime))
+		return
+	}
+	if len(os.Args) > 2 && os.Args[1] == "honeypot" {
+		CheckHoneypot(session, common.HexToAddress(os.Args[2]))
+		fmt.Println("Execution time: ", time.Now().Sub(startTime))
+		return
+	}
+	if len(os.Args) > 4 && os.Args[1] == "whatif" {
+		WhatIfTx(session, common.HexToHash(os.Args[2]), os.Args[3], os.Args[4])
+		fmt.Println("Execution time: ", time.Now().Sub(startTime))
+		return
+	}
+
+	if len(os.Args) > 2 && os.Args[1] == "bench" {
+		n, err := strconv.Atoi(os.Args[2])
+		if err != nil {
+			panic(err)
+		}
+		inputData := InputData
+		if len(os.Args) > 3 {
+			inputData = os.Args[3]
+		}
+		// Not through the session: its cache would answer all but the
+		// first simulation.
+		BenchSwap(pool, *commonContract, inputData, n)
+		fmt.Println("Execution time: ", time.Now().Sub(startTime))
+		return
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "sweep" {
+		inputData, args := InputData, os.Args[2:]
+		if len(args) > 0 && strings.HasPrefix(args[0], "0x") {
+			inputData, args = args[0], args[1:]
+		}
+		amounts := SweepAmounts
+		if len(args) > 0 {
+			amounts = make([]float64, len(args))
+			for i, arg := range args {
+				if amounts[i], err = strconv.ParseFloat(arg, 64); err != nil {
+					panic(err)
+				}
+			}
+		}
+		SweepSwap(session, *commonContract, inputData, amounts)
+		return
+	}
+
+	if len(os.Args) > 3 && os.Args[1] == "maxfill" {
+		inputData, args := InputData, os.Args[2:]
+		if strings.HasPrefix(args[0], "0x") {
+			inputData, args = args[0], args[1:]
+		}
+		bounds := make([]float64, len(args))
+		for i, arg := range args {
+			if bounds[i], err = strconv.ParseFloat(arg, 64); err != nil {
+				panic(err)
+			}
+		}
+		if len(bounds) < 2 {
+			panic("maxfill needs <low> <high> after the calldata")
+		}
+		maxImpact := 0.0
+		if len(bounds) > 2 {
+			maxImpact = bounds[2]
+		}
+		MaxFill(session, *commonContract, inputData, bounds[0], bounds[1], maxImpact)
+		fmt.Println("Execution time: ", time.Now().Sub(startTime))
+		return
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "gas" {
+		inputData := InputData
+		if len(os.Args) > 2 {
+			inputData = os.Args[2]
+		}
+		EstimateSwapGas(session, *commonContract, inputData)
+		return
+	}
+
+	inputData := InputData
+	if len(os.Args) > 2 && os.Args[1] == "swap" {
+		inputData = os.Args[2]
+	}
+	// Token in/out and the ETH to send come from the swap itself, so
+	// ETH->token and token->ETH swaps work the same as token->token.
+	swapCall, err := aggregator.DecodeSwapCall(hexutil.MustDecode(inputData))
 	if err != nil {
 		panic(err)
 	}
+
+	// Generate EncodedSwapData
 	ab, err := abi.JSON(bytes.NewBufferString(simswap.ContractMetaData.ABI))
 	if err != nil {
 		panic(err)
 	}
-	data, err := ab.Pack("simswap", DAIContract, KNCContract, Router, hexutil.MustDecode(InputData))
+	data, err := ab.Pack("simswap", swapCall.Desc.SrcToken, swapCall.Desc.DstToken, Router, hexutil.MustDecode(inputData))
 	if err != nil {
 		panic(err)
 	}
-	msg := ethereum.CallMsg{
-		From:      MyWallet,
-		To:        &SimSwapAddress,
-		Gas:       1000000,
-		GasPrice:  FloatToTokenAmount(100, 9),
-		GasFeeCap: FloatToTokenAmount(100, 9),
-		GasTipCap: FloatToTokenAmount(100, 9),
-		Value:     big.NewInt(0),
-		Data:      data,
+	gas := hexutil.Uint64(1000000)
+	input := hexutil.Bytes(data)
+	args := simulation.CallArgs{
+		From:     &MyWallet,
+		To:       &SimSwapAddress,
+		Gas:      &gas,
+		GasPrice: (*hexutil.Big)(FloatToTokenAmount(100, 9)),
+		Value:    (*hexutil.Big)(swapCall.Value()),
+		Data:     &input,
 	}
-	res, err := simClient.CallContract(context.Background(), msg, nil)
+	// The session goes through the pool, so the swap fails over like every
+	// other call.
+	res, err := simulation.NewSimulator(session, *commonContract).Simulate(context.Background(), args, nil)
 	if err != nil {
 		panic(err)
 	}
+	if res.Failed {
+		panic(res.RevertReason)
+	}
 
-	result, err := ab.Unpack("simswap", res)
+	result, err := simulation.UnpackSwapResult(res.ReturnData)
 	if err != nil {
 		panic(err)
 	}
-	fmt.Println("result'", result)
+	fmt.Println("result", result)
+	PrintSwapAmounts(session, swapCall.Desc.SrcToken, swapCall.Desc.DstToken, result)
 
 	fmt.Println("Execution time: ", time.Now().Sub(startTime))
 }
 
-func NewClient(rpcURL string, simAddress common.Address, commonContract *OverrideAccounts) (*ethclient.Client, error) {
-	httpClient := http.DefaultClient
+// BenchSwap runs the SimSwap simulation of inputData n times on a
+// simulation.Pool of BenchWorkers and prints the failures and throughput.
+func BenchSwap(client simulation.Client, overrides simulation.OverrideAccounts, inputData string, n int) {
+	swapCall, err := aggregator.DecodeSwapCall(hexutil.MustDecode(inputData))
+	if err != nil {
+		panic(err)
+	}
+	ab, err := abi.JSON(bytes.NewBufferString(simswap.ContractMetaData.ABI))
+	if err != nil {
+		panic(err)
+	}
+	data, err := ab.Pack("simswap", swapCall.Desc.SrcToken, swapCall.Desc.DstToken, Router, hexutil.MustDecode(inputData))
+	if err != nil {
+		panic(err)
+	}
+	gas := hexutil.Uint64(1000000)
+	input := hexutil.Bytes(data)
+	args := simulation.CallArgs{From: &MyWallet, To: &SimSwapAddress, Gas: &gas, Value: (*hexutil.Big)(swapCall.Value()), Data: &input}
+	run := func(ctx context.Context, sim *simulation.Simulator) (interface{}, error) {
+		res, err := sim.Simulate(ctx, args, nil)
+		if err != nil {
+			return nil, err
+		}
+		if res.Failed {
+			return nil, errors.New(res.RevertReason)
+		}
+		return simulation.UnpackSwapResult(res.ReturnData)
+	}
+
+	ctx := context.Background()
+	pool := simulation.NewPool(ctx, simulation.NewSimulator(client, overrides), simulation.PoolConfig{Workers: BenchWorkers, Timeout: BenchTimeout})
+	start := time.Now()
+	go func() {
+		defer pool.Close()
+		for i := 0; i < n; i++ {
+			if err := pool.Submit(ctx, simulation.Job{ID: strconv.Itoa(i), Run: run}); err != nil {
+				return
+			}
+		}
+	}()
+	failed := 0
+	for result := range pool.Results() {
+		if result.Err != nil {
+			failed++
+			fmt.Println("job", result.ID, "failed:", result.Err)
+		}
+	}
+	elapsed := time.Since(start)
+	fmt.Printf("%d simulations, %d failed, %.1f/s\n", n, failed, float64(n)/elapsed.Seconds())
+}
+
+// SweepSwap simulates inputData at each amount, in whole source tokens, and
+// prints the output curve as CSV.
+func SweepSwap(client simulation.Client, overrides simulation.OverrideAccounts, inputData string, amounts []float64) {
+	registry, err := simulation.LoadRegistry(RegistryPath)
+	if err != nil {
+		panic(err)
+	}
+	data := hexutil.MustDecode(inputData)
+	swapCall, err := aggregator.DecodeSwapCall(data)
+	if err != nil {
+		panic(err)
+	}
+	tokenIn, tokenOut := swapCall.Desc.SrcToken, swapCall.Desc.DstToken
+	sim := simulation.NewSimulator(client, overrides)
+	ctx := context.Background()
+	tokens, err := simulation.NewMetadataCache(sim).Get(ctx, tokenIn, tokenOut)
+	if err != nil {
+		panic(err)
+	}
+
+	req := simulation.SweepRequest{
+		Swap:     simulation.SwapRequest{From: MyWallet, TokenIn: tokenIn, TokenOut: tokenOut, Router: Router, Data: data},
+		Registry: registry,
+		ChainID:  ChainID,
+		Workers:  BenchWorkers,
+	}
+	for _, amount := range amounts {
+		req.Amounts = append(req.Amounts, FloatToTokenAmount(amount, int64(tokens[tokenIn].Decimals)))
+	}
+	points, err := sim.Sweep(ctx, req, nil)
+	if err != nil {
+		panic(err)
+	}
+	if err := simulation.WriteSweepCSV(os.Stdout, points, tokens[tokenIn], tokens[tokenOut]); err != nil {
+		panic(err)
+	}
+}
+
+// MaxFill searches the largest amount of inputData's source token between
+// low and high, in whole tokens, that still fills at the calldata's minimum
+// rate and, if maxImpact is positive, within that price impact.
+func MaxFill(client simulation.Client, overrides simulation.OverrideAccounts, inputData string, low, high, maxImpact float64) {
+	registry, err := simulation.LoadRegistry(RegistryPath)
+	if err != nil {
+		panic(err)
+	}
+	data := hexutil.MustDecode(inputData)
+	swapCall, err := aggregator.DecodeSwapCall(data)
+	if err != nil {
+		panic(err)
+	}
+	tokenIn, tokenOut := swapCall.Desc.SrcToken, swapCall.Desc.DstToken
+	sim := simulation.NewSimulator(client, overrides)
+	ctx := context.Background()
+	tokens, err := simulation.NewMetadataCache(sim).Get(ctx, tokenIn, tokenOut)
+	if err != nil {
+		panic(err)
+	}
+
+	decimals := int64(tokens[tokenIn].Decimals)
+	fill, err := sim.MaxFill(ctx, simulation.FillRequest{
+		Swap:        simulation.SwapRequest{From: MyWallet, TokenIn: tokenIn, TokenOut: tokenOut, Router: Router, Data: data},
+		Registry:    registry,
+		ChainID:     ChainID,
+		Low:         FloatToTokenAmount(low, decimals),
+		High:        FloatToTokenAmount(high, decimals),
+		KeepMinRate: true,
+		MaxImpact:   maxImpact,
+	}, nil)
+	if err != nil {
+		panic(err)
+	}
+	if fill.Amount == nil {
+		fmt.Println("nothing fills:", fill.Limit)
+		return
+	}
+	fmt.Println("maxAmount", tokens[tokenIn].FormatAmount(fill.Amount), "out", tokens[tokenOut].FormatAmount(fill.Result.AmountOut))
+	fmt.Println("impact", fill.Impact.Text('f', 6), "steps", fill.Steps)
+	if fill.Limit != "" {
+		fmt.Println("limit", fill.Limit)
+	}
+}
+
+// EstimateSwapGas estimates the gas limit of sending inputData to the router
+// from MyWallet, funded and approved for the swap through the overrides.
+func EstimateSwapGas(client simulation.Client, overrides simulation.OverrideAccounts, inputData string) {
+	registry, err := simulation.LoadRegistry(RegistryPath)
+	if err != nil {
+		panic(err)
+	}
+	data := hexutil.Bytes(hexutil.MustDecode(inputData))
+	swapCall, err := aggregator.DecodeSwapCall(data)
+	if err != nil {
+		panic(err)
+	}
+	tokenIn, amount := swapCall.Desc.SrcToken, swapCall.Desc.Amount
+	if tokenIn == aggregator.NativeToken {
+		overrides = overrides.Merge(simulation.OverrideAccounts{MyWallet: {Balance: hexutil.EncodeBig(new(big.Int).Add(amount, Exp10(18)))}})
+	} else {
+		overrides = overrides.Merge(mustOverrides(registry.FundOverrides(ChainID, tokenIn, MyWallet, amount)))
+		overrides = overrides.Merge(mustOverrides(registry.ApproveOverrides(ChainID, tokenIn, MyWallet, Router, amount)))
+	}
 
-	sc, err := newSimClient(rpcURL, httpClient, commonContract)
+	sim := simulation.NewSimulator(client, overrides)
+	est, err := sim.EstimateGas(context.Background(), simulation.CallArgs{
+		From:  &MyWallet,
+		To:    &Router,
+		Value: (*hexutil.Big)(swapCall.Value()),
+		Data:  &data,
+	}, nil, simulation.GasConfig{})
 	if err != nil {
-		return nil, err
+		panic(err)
 	}
+	fmt.Println(est)
+	fmt.Println("gasLimit", est.Recommended, "steps", est.Steps)
+}
 
-	return sc, nil
+// PrintSwapAmounts prints the swapped amounts with token symbols and decimals.
+func PrintSwapAmounts(client simulation.Client, tokenIn, tokenOut common.Address, result *simulation.SwapResult) {
+	sim := simulation.NewSimulator(client, nil)
+	tokens, err := simulation.NewMetadataCache(sim).Get(context.Background(), tokenIn, tokenOut)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println("amountIn", tokens[tokenIn].FormatAmount(result.AmountIn))
+	fmt.Println("amountOut", tokens[tokenOut].FormatAmount(result.AmountOut))
 }
 
-func newSimClient(url string, client *http.Client, commonContract *OverrideAccounts) (*ethclient.Client, error) {
-	round, err := newRoundTripExt(client, commonContract)
+// DecodeSwap prints a human-readable view of aggregation router swap calldata.
+func DecodeSwap(calldata string) {
+	call, err := aggregator.DecodeSwapCall(hexutil.MustDecode(calldata))
 	if err != nil {
-		return nil, err
+		panic(err)
 	}
+	fmt.Print(call)
+}
+
+// SimulateRawTx simulates a signed raw transaction (hex encoded) at the latest
+// block, without state overrides.
+func SimulateRawTx(client simulation.Client, rawTx string) {
+	raw, err := hexutil.Decode(rawTx)
+	if err != nil {
+		fmt.Println("invalid raw transaction:", err)
+		return
+	}
+	sim := simulation.NewSimulator(client, nil)
+	res, err := sim.SimulateRawTransaction(context.Background(), raw, nil, nil, true)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println("failed", res.Failed)
+	if res.Failed {
+		fmt.Println("revertReason", res.RevertReason)
+	}
+	fmt.Println("returnData", hexutil.Encode(res.ReturnData))
+}
 
-	cc := &http.Client{Transport: round}
-	r, err := rpc.DialHTTPWithClient(url, cc)
+// ReplayTx re-simulates a mined transaction and prints how the simulation
+// differs from its receipt. The node must serve debug_traceCall.
+func ReplayTx(client simulation.Client, txHash common.Hash) {
+	sim := simulation.NewSimulator(client, nil)
+	res, err := sim.Replay(context.Background(), txHash)
 	if err != nil {
-		return nil, errors.WithMessage(err, "simclient: dial rpc")
+		panic(err)
+	}
+	fmt.Println("block", res.BlockNumber)
+	fmt.Println("prestateApplied", res.PrestateApplied)
+	if res.FeesDropped {
+		fmt.Println("feesDropped", res.FeesDropped)
+	}
+	fmt.Println("status", res.Receipt.Status, "simulatedFailed", res.Simulated.Failed)
+	fmt.Println("gasUsed", res.Receipt.GasUsed, "simulatedGasUsed", res.Simulated.GasUsed)
+	fmt.Println("logs", len(res.Receipt.Logs), "simulatedLogs", len(res.Simulated.Logs))
+	if res.Match() {
+		fmt.Println("simulation matches receipt")
+		return
 	}
+	for _, diff := range res.Diffs {
+		fmt.Println("diff", diff)
+	}
+}
 
-	ethClient := ethclient.NewClient(r)
+// WhatIfTx re-simulates a mined or pending transaction with one field changed
+// and prints both outcomes side by side. field is one of from, amount,
+// minReturn, recipient, gasPrice or timestamp.
+func WhatIfTx(client simulation.Client, txHash common.Hash, field string, value string) {
+	var mod simulation.Modification
+	switch field {
+	case "from":
+		from := common.HexToAddress(value)
+		mod.From = &from
+	case "amount":
+		mod.Amount = hexutil.MustDecodeBig(value)
+	case "minReturn":
+		mod.MinReturnAmount = hexutil.MustDecodeBig(value)
+	case "recipient":
+		recipient := common.HexToAddress(value)
+		mod.DstReceiver = &recipient
+	case "gasPrice":
+		mod.GasPrice = hexutil.MustDecodeBig(value)
+	case "timestamp":
+		timestamp := hexutil.MustDecodeUint64(value)
+		mod.Timestamp = &timestamp
+	default:
+		panic("unknown field " + field)
+	}
 
-	return ethClient, nil
+	sim := simulation.NewSimulator(client, nil)
+	res, err := sim.WhatIf(context.Background(), txHash, mod)
+	if err != nil {
+		panic(err)
+	}
+	for _, diff := range res.Diffs {
+		marker := " "
+		if diff.Changed() {
+			marker = "*"
+		}
+		fmt.Printf("%s %-10s | %s | %s\n", marker, diff.Field, diff.Original, diff.Modified)
+	}
 }
 
-func newRoundTripExt(c *http.Client, accounts *OverrideAccounts) (http.RoundTripper, error) {
-	data, err := json.Marshal(accounts)
+// AnalyzeToken test-transfers 1000 tokens (18 decimals assumed) and prints
+// whether the token is standard, fee-on-transfer, rebasing or blocked.
+// balanceSlot is the decimal slot of the token's balances mapping.
+func AnalyzeToken(client simulation.Client, token common.Address, balanceSlot string) {
+	slot, ok := new(big.Int).SetString(balanceSlot, 10)
+	if !ok {
+		panic("invalid balance slot " + balanceSlot)
+	}
+	sim := simulation.NewSimulator(client, nil)
+	report, err := sim.AnalyzeToken(context.Background(), token, slot, FloatToTokenAmount(1000, 18), nil)
 	if err != nil {
-		return nil, err
+		panic(err)
 	}
-	return &roundTripperExt{
-		c:          c,
-		appendData: data,
-	}, nil
+	fmt.Println("token", report)
+	fmt.Println("sent", report.Sent, "received", report.Received)
 }
 
-func (r roundTripperExt) RoundTrip(request *http.Request) (*http.Response, error) {
-	// Trick: append Config OrderrideAcount to eth_call
-	rt := request.Clone(context.Background())
-	body, _ := ioutil.ReadAll(request.Body)
-	_ = request.Body.Close()
-	if len(body) > 0 {
-		rt.Body = ioutil.NopCloser(bytes.NewBuffer(body))
-		request.Body = ioutil.NopCloser(bytes.NewBuffer(body))
-	}
-	var req reqMessage
-	if err := json.Unmarshal(body, &req); err == nil {
-		if req.Method == "eth_call" {
-			req.Params = append(req.Params, r.appendData)
+// VerifySlots checks the registered Solidity slots of ChainID against
+// balanceOf and allowance of MyWallet.
+func VerifySlots(client simulation.Client) {
+	registry, err := simulation.LoadRegistry(RegistryPath)
+	if err != nil {
+		panic(err)
+	}
+	sim := simulation.NewSimulator(client, nil)
+	var checks []simulation.SlotCheck
+	for _, layout := range registry.Tokens() {
+		if layout.ChainID != ChainID || layout.Layout != simulation.LayoutSolidity {
+			continue
 		}
-		d2, err := json.Marshal(req)
-		if err != nil {
-			panic(err)
+		check := simulation.SlotCheck{Token: layout.Token, Owner: MyWallet, Spender: Router}
+		if layout.BalanceSlot != nil {
+			check.BalanceSlot = new(big.Int).SetUint64(*layout.BalanceSlot)
+		}
+		if layout.AllowanceSlot != nil {
+			check.AllowanceSlot = new(big.Int).SetUint64(*layout.AllowanceSlot)
+		}
+		checks = append(checks, check)
+	}
+	reports, err := sim.StateReader().VerifySlots(context.Background(), checks, nil)
+	if err != nil {
+		panic(err)
+	}
+	for _, report := range reports {
+		switch {
+		case !report.OK():
+			fmt.Println(report.Check.Token.Hex(), "mismatch", report.Mismatches())
+		case report.Inconclusive():
+			fmt.Println(report.Check.Token.Hex(), "inconclusive: all values are zero")
+		default:
+			fmt.Println(report.Check.Token.Hex(), "ok at block", report.BlockNumber)
 		}
-		rt.ContentLength = int64(len(d2))
-		rt.Body = ioutil.NopCloser(bytes.NewBuffer(d2))
 	}
-	return r.c.Do(rt)
+}
+
+// FindSlots searches the balance and allowance slots of token and records
+// them in the registry file.
+func FindSlots(client simulation.Client, token common.Address) {
+	registry, err := simulation.LoadRegistry(RegistryPath)
+	if err != nil {
+		panic(err)
+	}
+	sim := simulation.NewSimulator(client, nil)
+	layout, err := sim.FindAndRegister(context.Background(), registry, token, nil)
+	if err != nil {
+		panic(err)
+	}
+	if layout.BalanceSlot == nil {
+		fmt.Println("balance slot not found")
+	} else {
+		fmt.Println("layout", layout.Layout, "balanceSlot", *layout.BalanceSlot)
+	}
+	if layout.AllowanceSlot != nil {
+		fmt.Println("allowanceSlot", *layout.AllowanceSlot)
+	}
+	if layout.Proxy != nil {
+		fmt.Println("proxy", layout.Proxy.Kind, layout.Proxy.Implementation.Hex())
+	}
+	if err := registry.Save(RegistryPath); err != nil {
+		panic(err)
+	}
+}
+
+// CheckHoneypot buys the token for 0.1 ETH on Uniswap V2, sells it back and
+// prints the taxes and anything that makes it unsafe to route through.
+func CheckHoneypot(client simulation.Client, token common.Address) {
+	sim := simulation.NewSimulator(client, nil)
+	report, err := sim.CheckHoneypot(context.Background(), UniswapV2Router, token, FloatToTokenAmount(0.1, 18), nil)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println("bought", report.Bought, "quoted", report.BuyExpected)
+	fmt.Println("sold", report.Sold, "quoted", report.SellExpected)
+	fmt.Println("token", report)
 }
 
 func FloatToTokenAmount(amount float64, decimals int64) *big.Int {
@@ -221,54 +673,3 @@ func FloatToTokenAmount(amount float64, decimals int64) *big.Int {
 func Exp10(n int64) *big.Int {
 	return new(big.Int).Exp(big.NewInt(expBase), big.NewInt(n), nil)
 }
-
-func toHashString(hexStr string) string {
-	str := strings.Replace(hexStr, "0x", "", -1)
-	str = strings.ToLower(str)
-	return fmt.Sprintf("%064v", str)
-}
-
-func getIndexBalanceOf(owner string, slot string) common.Hash {
-	slot = toHashString(slot)
-	owner = toHashString(owner)
-
-	index := solsha3.SoliditySHA3(
-		// types
-		[]string{"address", "uint256"},
-
-		// values
-		[]interface{}{
-			owner,
-			slot,
-		},
-	)
-	return common.BytesToHash(index)
-}
-
-func getIndexAllowance(owner string, spender string, slot string) common.Hash {
-	slot = toHashString(slot)
-	owner = toHashString(owner)
-	temp := solsha3.SoliditySHA3(
-		// types
-		[]string{"address", "uint256"},
-
-		// values
-		[]interface{}{
-			owner,
-			slot,
-		},
-	)
-	tempStr := toHashString(common.BytesToHash(temp).String())
-	spender = toHashString(spender)
-	index := solsha3.SoliditySHA3(
-		// types
-		[]string{"address", "address"},
-
-		// values
-		[]interface{}{
-			spender,
-			tempStr,
-		},
-	)
-	return common.BytesToHash(index)
-}
@@ -4,10 +4,14 @@ import (
 	"context"
 	"encoding/hex"
 	"fmt"
+	"geth/aggregator"
 	"geth/contract/aggregation_router"
-	"geth/contract/dai"
+	"geth/contract/erc20"
+	"geth/rpcproxy"
+	"geth/simulation"
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/ethereum/go-ethereum/rpc"
@@ -75,16 +79,20 @@ type RpcClient struct {
 }
 
 var (
-	daiContract      = "0x6b175474e89094c44da98b954eedeac495271d0f"
-	daiBalanceOfSlot = "2"
-	daiAllowanceSlot = "3"
+	daiContract = "0x6b175474e89094c44da98b954eedeac495271d0f"
+	kncContract = "0xdeFA4e8a7bcBA345F687a2f1456F5Edd9CE97202"
 
-	kncContract      = "0xdeFA4e8a7bcBA345F687a2f1456F5Edd9CE97202"
-	kncAllowanceSlot = "102"
+	// registryPath holds the storage slots of daiContract and kncContract.
+	registryPath = "tokens.json"
 
 	router = "0x00555513acf282b42882420e5e5ba87b44d8fa6e"
 	wallet = "0xef09879057a9ad798438f3ba561bcdd293d72fc7"
 
+	// walletOverrides funds wallet in every call made through NewRPCClient.
+	walletOverrides = &simulation.Overrides{StateOverrides: simulation.OverrideAccounts{
+		common.HexToAddress(wallet): {Balance: "0x56BC75E2D63100000"},
+	}}
+
 	encodedSwapData = "0xabcffc2600000000000000000000000041684b361557e9282e0373ca51260d9331e518c90000000000000000000000000000000000000000000000000000000000000080000000000000000000000000000000000000000000000000000000000000024000000000000000000000000000000000000000000000000000000000000008000000000000000000000000006b175474e89094c44da98b954eedeac495271d0f000000000000000000000000defa4e8a7bcba345f687a2f1456f5edd9ce9720200000000000000000000000000000000000000000000000000000000000001200000000000000000000000000000000000000000000000000000000000000160000000000000000000000000198c08797dd4341f738ec18fcd05d64f645b822800000000000000000000000000000000000000000000003635c9adc5dea00000000000000000000000000000000000000000000000000020a1691d08bc8f7727000000000000000000000000000000000000000000000000000000000000000400000000000000000000000000000000000000000000000000000000000001a0000000000000000000000000000000000000000000000000000000000000000100000000000000000000000041684b361557e9282e0373ca51260d9331e518c9000000000000000000000000000000000000000000000000000000000000000100000000000000000000000000000000000000000000003635c9adc5dea00000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000005a0000000000000000000000000000000000000000000000000000000000000002000000000000000000000000000000000000000000000000000000000000000e00000000000000000000000006b175474e89094c44da98b954eedeac495271d0f000000000000000000000000defa4e8a7bcba345f687a2f1456f5edd9ce97202000000000000000000000000000000000000000000000020a1691d08bc8f7727000000000000000000000000198c08797dd4341f738ec18fcd05d64f645b82280000000000000000000000000000000000000000000000000000000062fcb79500000000000000000000000000000000000000000000000000000000000005600000000000000000000000000000000000000000000000000000000000000001000000000000000000000000000000000000000000000000000000000000002000000000000000000000000000000000000000000000000000000000000000030000000000000000000000000000000000000000000000000000000000000060000000000000000000000000000000000000000000000000000000000000018000000000000000000000000000000000000000000000000000000000000003000000000000000000000000000000000000000000000000000000000000000040000000000000000000000000000000000000000000000000000000000000060100000000000000000000000000000000000000000000000000000000000000c0000000000000000000000000ba12222222228d8ba445958a75a0704d566bf2c806df3b2bbb68adc8b0e302443692037ed9f91b420000000000000000000000630000000000000000000000006b175474e89094c44da98b954eedeac495271d0f000000000000000000000000dac17f958d2ee523a2206206994597c13d831ec700000000000000000000000000000000000000000000003635c9adc5dea000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000004000000000000000000000000000000000000000000000000000000000000002010000000000000000000000000000000000000000000000000000000000000120000000000000000000000000d51a44d3fae010294c616388b506acda1bfaae46000000000000000000000000dac17f958d2ee523a2206206994597c13d831ec7000000000000000000000000c02aaa39b223fe8d0a0e5c4f27ead9083c756cc200000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000002000000000000000000000000000000000000000000000000000000003b976e460000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000010000000000000000000000000000000000000000000000000000000000000040000000000000000000000000000000000000000000000000000000000000030000000000000000000000000000000000000000000000000000000000000000c000000000000000000000000061639d6ec06c13a96b5eb9560b359d7c648c7759000000000000000000000000c02aaa39b223fe8d0a0e5c4f27ead9083c756cc2000000000000000000000000defa4e8a7bcba345f687a2f1456f5edd9ce97202000000000000000000000000198c08797dd4341f738ec18fcd05d64f645b8228000000000000000000000000000000000000000000000000000000000000000100000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000004000000000000000000000000000000000000000000000000000000000000000200000000000000000000000000000000000000000000000000000000000000000"
 )
 
@@ -93,6 +101,12 @@ func main() {
 	// NOTE update the path to the ipc file!
 
 	startTime := time.Now()
+	swapCall, err := aggregator.DecodeSwapCall(hexutil.MustDecode(encodedSwapData))
+	if err != nil {
+		panic(err)
+	}
+	fmt.Print(swapCall)
+
 	client := NewRPCClient("/Users/nguyenducminh/ethdata/geth.ipc")
 	//client := NewRPCClient("/Users/nguyenducminh/Library/Ethereum/goerli/geth.ipc")
 	//client.GetTokenBalanceOf()
@@ -102,10 +116,27 @@ func main() {
 	fmt.Println("Execution time: ", time.Now().Sub(startTime))
 }
 
+// slotOf returns the registered mainnet balance (or allowance) slot of token.
+func slotOf(token string, allowance bool) string {
+	registry, err := simulation.LoadRegistry(registryPath)
+	if err != nil {
+		panic(err)
+	}
+	layout, ok := registry.Lookup(1, common.HexToAddress(token))
+	slot := layout.BalanceSlot
+	if allowance {
+		slot = layout.AllowanceSlot
+	}
+	if !ok || slot == nil {
+		panic("no slot registered for " + token)
+	}
+	return strconv.FormatUint(*slot, 10)
+}
+
 func NewRPCClient(address string) *RpcClient {
 	var err error
 	rpcClient := &RpcClient{}
-	rpcClient.Client, err = rpc.Dial(address)
+	rpcClient.Client, err = rpcproxy.Dial(context.Background(), address, walletOverrides)
 	if err != nil {
 		panic(err)
 	}
@@ -117,11 +148,11 @@ func NewRPCClient(address string) *RpcClient {
 }
 
 func (rc *RpcClient) GetTokenBalanceOf() {
-	indexDaiBalanceOf := rc.GetIndexBalanceOf(wallet, daiBalanceOfSlot)
+	indexDaiBalanceOf := rc.GetIndexBalanceOf(wallet, slotOf(daiContract, false))
 	fmt.Println("indexDaiBalanceOf", indexDaiBalanceOf)
 	rc.GetBalanceOf(daiContract, indexDaiBalanceOf)
 
-	indexDaiAllowance := rc.GetIndexAllowance(wallet, router, daiAllowanceSlot)
+	indexDaiAllowance := rc.GetIndexAllowance(wallet, router, slotOf(daiContract, true))
 	fmt.Println("indexDaiAllowance", indexDaiAllowance)
 
 	fakeBalance := "0x" + toHashString("0x130EE8E7179044400000")
@@ -142,9 +173,6 @@ func (rc *RpcClient) GetTokenBalanceOf() {
 	}
 
 	configEthCall := StateOverride{
-		"0xef09879057a9ad798438f3ba561bcdd293d72fc7": OverrideAccount{
-			Balance: "0x56BC75E2D63100000",
-		},
 		daiContract: OverrideAccount{
 			StateDiff: map[string]string{
 				indexDaiBalanceOf.String(): fakeBalance,
@@ -184,14 +212,14 @@ func (rc *RpcClient) GetStructLogs() []StructLog {
 	//	Input:    "0xe8927fbc",
 	//}
 
-	indexDaiBalanceOf := rc.GetIndexBalanceOf(wallet, daiBalanceOfSlot)
+	indexDaiBalanceOf := rc.GetIndexBalanceOf(wallet, slotOf(daiContract, false))
 	fmt.Println("indexDaiBalanceOf", indexDaiBalanceOf)
 	//rc.GetBalanceOf(daiContract, indexDaiBalanceOf)
 
-	indexDaiAllowance := rc.GetIndexAllowance(wallet, router, daiAllowanceSlot)
+	indexDaiAllowance := rc.GetIndexAllowance(wallet, router, slotOf(daiContract, true))
 	fmt.Println("indexDaiAllowance", indexDaiAllowance)
 
-	indexKncAllowance := rc.GetIndexAllowance(wallet, router, kncAllowanceSlot)
+	indexKncAllowance := rc.GetIndexAllowance(wallet, router, slotOf(kncContract, true))
 	fmt.Println("indexKncAllowance", indexKncAllowance)
 
 	fakeBalance := "0x" + toHashString("0x130EE8E7179044400000")
@@ -208,9 +236,6 @@ func (rc *RpcClient) GetStructLogs() []StructLog {
 		//Tracer:           "loggetter",
 		Timeout: "20s",
 		StateOverrides: StateOverride{
-			"0xef09879057a9ad798438f3ba561bcdd293d72fc7": OverrideAccount{
-				Balance: "0x56BC75E2D63100000",
-			},
 			daiContract: OverrideAccount{
 				StateDiff: map[string]string{
 					indexDaiBalanceOf.String(): fakeBalance,
@@ -470,7 +495,7 @@ func (rc *RpcClient) GetBalanceOf(contractAddress string, index common.Hash) {
 		panic(err)
 	}
 
-	contractAbi, err := abi.JSON(strings.NewReader(dai.ContractMetaData.ABI))
+	contractAbi, err := abi.JSON(strings.NewReader(erc20.ContractMetaData.ABI))
 	if err != nil {
 		panic(err)
 	}
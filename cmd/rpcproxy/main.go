@@ -0,0 +1,25 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"geth/rpcproxy"
+	"net/http"
+	"os"
+)
+
+func main() {
+	configPath := flag.String("config", "rpcproxy.json", "proxy config file")
+	flag.Parse()
+
+	config, err := rpcproxy.LoadConfig(*configPath)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	fmt.Println("rpcproxy listening on", config.Listen, "upstream", config.Upstream, config.UpstreamWS)
+	if err := http.ListenAndServe(config.Listen, rpcproxy.New(config)); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+}
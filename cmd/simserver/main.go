@@ -0,0 +1,192 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"geth/simulation"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/pkg/errors"
+	"math/big"
+	"net/http"
+	"os"
+	"time"
+)
+
+const (
+	// maxBodySize bounds a request body; calldata dominates it.
+	maxBodySize = 4 << 20
+	// simulateTimeout bounds the node calls of one request.
+	simulateTimeout = 30 * time.Second
+)
+
+// simulateRequest is the body of POST /simulate. Block is a tag ("latest",
+// "pending", "earliest") or a hex or decimal number.
+type simulateRequest struct {
+	Call           simulation.CallArgs         `json:"call"`
+	StateOverrides simulation.OverrideAccounts `json:"stateOverrides"`
+	BlockOverrides *simulation.BlockOverrides  `json:"blockOverrides"`
+	Block          string                      `json:"block"`
+	Trace          bool                        `json:"trace"`
+}
+
+type assetChange struct {
+	Token    common.Address `json:"token"`
+	Owner    common.Address `json:"owner"`
+	Symbol   string         `json:"symbol,omitempty"`
+	Decimals uint8          `json:"decimals"`
+	// Delta is the raw amount, Amount the amount in whole tokens.
+	Delta  string `json:"delta"`
+	Amount string `json:"amount"`
+}
+
+type simulateResponse struct {
+	Status       string                   `json:"status"`
+	Error        string                   `json:"error,omitempty"`
+	RevertReason string                   `json:"revertReason,omitempty"`
+	GasUsed      uint64                   `json:"gasUsed"`
+	ReturnData   hexutil.Bytes            `json:"returnData"`
+	Logs         []*simulation.DecodedLog `json:"logs"`
+	AssetChanges []assetChange            `json:"assetChanges"`
+	Trace        *simulation.CallFrame    `json:"trace,omitempty"`
+	// Block is the block "latest" resolved to.
+	Block *pinnedBlock `json:"block,omitempty"`
+}
+
+type pinnedBlock struct {
+	Number uint64      `json:"number"`
+	Hash   common.Hash `json:"hash"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+type server struct {
+	client   *rpc.Client
+	metadata *simulation.MetadataCache
+}
+
+func main() {
+	listen := flag.String("listen", ":8080", "address to serve on")
+	rawurl := flag.String("rpc", "http://localhost:8545", "node with debug_traceCall")
+	flag.Parse()
+
+	client, err := rpc.Dial(*rawurl)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	s := &server{client: client, metadata: simulation.NewMetadataCache(simulation.NewSimulator(client, nil))}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/simulate", s.handleSimulate)
+	fmt.Println("simserver listening on", *listen, "node", *rawurl)
+	if err := http.ListenAndServe(*listen, mux); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+}
+
+func (s *server) handleSimulate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeJSON(w, http.StatusMethodNotAllowed, errorResponse{"use POST"})
+		return
+	}
+	var req simulateRequest
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse{"decode request: " + err.Error()})
+		return
+	}
+	blockNumber, err := simulation.ParseBlockNumber(req.Block)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse{err.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), simulateTimeout)
+	defer cancel()
+	res, err := s.simulate(ctx, &req, blockNumber)
+	var reorg *simulation.ReorgError
+	if errors.As(err, &reorg) {
+		// The block was replaced while simulating; a retry pins the new one.
+		writeJSON(w, http.StatusConflict, errorResponse{err.Error()})
+		return
+	}
+	if err != nil {
+		writeJSON(w, http.StatusBadGateway, errorResponse{err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+// simulate traces the call for its logs, gas and transfers and, when asked,
+// once more with the call tracer. "latest" is pinned for both.
+func (s *server) simulate(ctx context.Context, req *simulateRequest, blockNumber *big.Int) (*simulateResponse, error) {
+	sim := simulation.NewSimulator(s.client, req.StateOverrides)
+	var block *pinnedBlock
+	if blockNumber == nil {
+		session, err := simulation.NewSession(ctx, s.client)
+		if err != nil {
+			return nil, err
+		}
+		sim = session.Simulator(req.StateOverrides)
+		block = &pinnedBlock{Number: session.Number(), Hash: session.Hash()}
+	}
+	traced, err := sim.Trace(ctx, req.Call, blockNumber, req.BlockOverrides)
+	if err != nil {
+		return nil, err
+	}
+	res := &simulateResponse{
+		Status:     "success",
+		GasUsed:    traced.GasUsed,
+		ReturnData: traced.ReturnData,
+		Logs:       make([]*simulation.DecodedLog, 0, len(traced.Logs)),
+		Block:      block,
+	}
+	if traced.Failed {
+		res.Status, res.Error = "reverted", traced.Error
+		if len(traced.ReturnData) > 0 {
+			res.RevertReason = simulation.DecodeRevert(traced.ReturnData)
+		}
+	}
+	for _, l := range traced.Logs {
+		res.Logs = append(res.Logs, simulation.DecodeLog(l))
+	}
+
+	changes := simulation.AssetChanges(traced)
+	tokens := make([]common.Address, 0, len(changes))
+	for _, change := range changes {
+		tokens = append(tokens, change.Token)
+	}
+	// Without metadata, e.g. for a token the call itself deploys, changes
+	// are still reported raw.
+	metadata, _ := s.metadata.Get(ctx, tokens...)
+	res.AssetChanges = make([]assetChange, 0, len(changes))
+	for _, change := range changes {
+		c := assetChange{Token: change.Token, Owner: change.Owner, Delta: change.Delta.String(), Amount: change.Delta.String()}
+		if m := metadata[change.Token]; m != nil {
+			c.Symbol, c.Decimals, c.Amount = m.Symbol, m.Decimals, simulation.FormatUnits(change.Delta, m.Decimals)
+		}
+		res.AssetChanges = append(res.AssetChanges, c)
+	}
+
+	if req.Trace {
+		if res.Trace, err = sim.CallTrace(ctx, req.Call, blockNumber, req.BlockOverrides); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
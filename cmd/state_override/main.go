@@ -5,14 +5,14 @@ import (
 	"encoding/hex"
 	"fmt"
 	"geth/contract/dai"
+	"geth/rpcproxy"
+	"geth/simulation"
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
-	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/ethereum/go-ethereum/rpc"
-	solsha3 "github.com/miguelmota/go-solidity-sha3"
 	"math/big"
 	"strconv"
 	"strings"
@@ -59,24 +59,13 @@ type TransactionArgs struct {
 	Value    string `json:"value"`
 }
 
-type OverrideAccount struct {
-	//Nonce     *hexutil.Uint64              `json:"nonce"`
-	//Code      *hexutil.Bytes               `json:"code"`
-	Balance string `json:"balance"`
-	//State     *map[common.Hash]common.Hash `json:"state"`
-	//StateDiff *map[common.Hash]common.Hash `json:"stateDiff"`
-}
-
-type StateOverride map[string]OverrideAccount
-
 type TraceConfig struct {
-	DisableStorage   bool          `json:"disableStorage"`
-	DisableStack     bool          `json:"disableStack"`
-	EnableMemory     bool          `json:"enableMemory"`
-	EnableReturnData bool          `json:"enableReturnData"`
-	Tracer           string        `json:"tracer"`
-	Timeout          string        `json:"timeout"`
-	StateOverrides   StateOverride `json:"stateOverrides"`
+	DisableStorage   bool   `json:"disableStorage"`
+	DisableStack     bool   `json:"disableStack"`
+	EnableMemory     bool   `json:"enableMemory"`
+	EnableReturnData bool   `json:"enableReturnData"`
+	Tracer           string `json:"tracer"`
+	Timeout          string `json:"timeout"`
 }
 
 type StructLog struct {
@@ -103,6 +92,7 @@ type DebugTraceCallResponse struct {
 
 type RpcClient struct {
 	Client *rpc.Client
+	State  *simulation.StateReader
 }
 
 func main() {
@@ -136,10 +126,15 @@ func main() {
 func NewRPCClient(address string) *RpcClient {
 	var err error
 	rpcClient := &RpcClient{}
-	rpcClient.Client, err = rpc.Dial(address)
+	rpcClient.Client, err = rpcproxy.Dial(context.Background(), address, &simulation.Overrides{
+		StateOverrides: simulation.OverrideAccounts{
+			common.HexToAddress("0xef09879057a9ad798438f3ba561bcdd293d72fc7"): {Balance: "0x56BC75E2D63100000"},
+		},
+	})
 	if err != nil {
 		panic(err)
 	}
+	rpcClient.State = simulation.NewStateReader(rpcClient.Client)
 	return rpcClient
 }
 
@@ -167,11 +162,6 @@ func (rc *RpcClient) GetStructLogs() []StructLog {
 		EnableReturnData: true,
 		Tracer:           "loggetter",
 		Timeout:          "20s",
-		StateOverrides: StateOverride{
-			"0xef09879057a9ad798438f3ba561bcdd293d72fc7": OverrideAccount{
-				Balance: "0x56BC75E2D63100000",
-			},
-		},
 	}
 
 	structLogs := make([]StructLog, 0, 0)
@@ -361,80 +351,22 @@ func toHashString(hexStr string) string {
 }
 
 func (rc *RpcClient) GetTotalSupply(contractAddress string, slot string) {
-	//intSlot, err := strconv.ParseUint(slot, 10, 64)
-	//if err != nil {
-	//	panic(err)
-	//}
-	//hexSlot := hexutil.EncodeUint64(intSlot)
-	fmt.Println("hexSlot", slot)
-
-	client, err := ethclient.Dial("/Users/nguyenducminh/ethdata/geth.ipc")
+	fmt.Println("--------GetTotalSupply")
+	value, err := rc.State.Slot(context.Background(), common.HexToAddress(contractAddress), common.HexToHash(slot), nil)
 	if err != nil {
 		panic(err)
 	}
-
-	fmt.Println("index", common.HexToHash(slot))
-
-	data, err := client.StorageAt(
-		context.Background(),
-		common.HexToAddress(contractAddress),
-		common.HexToHash(slot),
-		nil)
-	if err != nil {
-		panic(err)
-	}
-
-	// contractABI
-	contractAbi, err := abi.JSON(strings.NewReader(dai.ContractMetaData.ABI))
-	if err != nil {
-		panic(err)
-	}
-	totalSupply, err := contractAbi.Unpack("totalSupply", data)
-	if err != nil {
-		panic(err)
-	}
-	fmt.Println("totalSupply", totalSupply)
+	fmt.Println("totalSupply", value.Big())
 }
 
 func (rc *RpcClient) GetBalanceOf(contractAddress string, owner string, slot string) {
 	fmt.Println("--------GetBalanceOf")
-	client, err := ethclient.Dial("/Users/nguyenducminh/ethdata/geth.ipc")
-	if err != nil {
-		panic(err)
-	}
-
-	slot = toHashString(slot)
-	fmt.Println("slot", slot)
-
-	owner = toHashString(owner)
-	fmt.Println("owner", owner)
-
-	index := solsha3.SoliditySHA3(
-		// types
-		[]string{"address", "uint256"},
-
-		// values
-		[]interface{}{
-			owner,
-			slot,
-		},
-	)
-
-	fmt.Println("Index", index)
-
-	state, err := client.StorageAt(
+	balanceOf, err := rc.State.BalanceOfSlot(
 		context.Background(),
 		common.HexToAddress(contractAddress),
-		common.BytesToHash(index), nil)
-	if err != nil {
-		panic(err)
-	}
-
-	contractAbi, err := abi.JSON(strings.NewReader(dai.ContractMetaData.ABI))
-	if err != nil {
-		panic(err)
-	}
-	balanceOf, err := contractAbi.Unpack("balanceOf", state)
+		common.HexToAddress(owner),
+		parseSlot(slot),
+		nil)
 	if err != nil {
 		panic(err)
 	}
@@ -442,53 +374,25 @@ func (rc *RpcClient) GetBalanceOf(contractAddress string, owner string, slot str
 }
 
 func (rc *RpcClient) GetAllowanceIndex(contractAddress string, owner string, spender string, slot string) {
-	fmt.Println("--------_GetAllowanceIndex")
-	client, err := ethclient.Dial("/Users/nguyenducminh/ethdata/geth.ipc")
-	if err != nil {
-		panic(err)
-	}
-
-	slot = toHashString(slot)
-	fmt.Println("slot", slot)
-	owner = toHashString(owner)
-	fmt.Println("owner", owner)
-	temp := solsha3.SoliditySHA3(
-		// types
-		[]string{"address", "uint256"},
-
-		// values
-		[]interface{}{
-			owner,
-			slot,
-		},
-	)
-
-	tempStr := toHashString(common.BytesToHash(temp).String())
-	spender = toHashString(spender)
-	fmt.Println("spender", spender)
-	index := solsha3.SoliditySHA3(
-		// types
-		[]string{"address", "address"},
-
-		// values
-		[]interface{}{
-			spender,
-			tempStr,
-		},
-	)
-
-	state, err := client.StorageAt(context.Background(), common.HexToAddress(contractAddress), common.BytesToHash(index), nil)
+	fmt.Println("--------GetAllowanceIndex")
+	allowance, err := rc.State.AllowanceSlot(
+		context.Background(),
+		common.HexToAddress(contractAddress),
+		common.HexToAddress(owner),
+		common.HexToAddress(spender),
+		parseSlot(slot),
+		nil)
 	if err != nil {
 		panic(err)
 	}
+	fmt.Println("allowance", allowance)
+}
 
-	contractAbi, err := abi.JSON(strings.NewReader(dai.ContractMetaData.ABI))
-	if err != nil {
-		panic(err)
+// parseSlot parses a decimal slot number.
+func parseSlot(slot string) *big.Int {
+	n, ok := new(big.Int).SetString(slot, 10)
+	if !ok {
+		panic("invalid slot " + slot)
 	}
-	allowance, err := contractAbi.Unpack("allowance", state)
-	if err != nil {
-		panic(err)
-	}
-	fmt.Println("allowance", allowance)
+	return n
 }
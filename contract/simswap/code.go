@@ -0,0 +1,6 @@
+package simswap
+
+// RuntimeCode is the deployed bytecode of sol/SimSwap.sol (solc 0.8.21,
+// evmVersion london, optimizer off). It is injected as a code override at the
+// simulation address.
+const RuntimeCode = "0x60806040526004361061007f5760003560e01c80637e5465ba1161004e5780637e5465ba1461015c57806389d834971461019957806396d27420146101db578063ad5b26ee1461020f57610086565b806302ad27761461008b57806321c4f09f146100be5780635342940b146100ee578063681161771461012c57610086565b3661008657005b600080fd5b6100a560048036038101906100a09190611797565b610245565b6040516100b594939291906119b8565b60405180910390f35b6100d860048036038101906100d39190611a0b565b61069b565b6040516100e59190611a4b565b60405180910390f35b3480156100fa57600080fd5b5061011560048036038101906101109190611ae8565b610721565b604051610123929190611d47565b60405180910390f35b61014660048036038101906101419190611d7e565b61092e565b6040516101539190611a4b565b60405180910390f35b34801561016857600080fd5b50610183600480360381019061017e9190611a0b565b610941565b6040516101909190611a4b565b60405180910390f35b3480156101a557600080fd5b506101c060048036038101906101bb9190611dab565b6109f1565b6040516101d296959493929190611dfe565b60405180910390f35b6101f560048036038101906101f09190611e66565b610b48565b604051610206959493929190611eee565b60405180910390f35b61022960048036038101906102249190611a0b565b610d9e565b60405161023c9796959493929190611f48565b60405180910390f35b60606000806060610255876114b6565b610294576040517f08c379a000000000000000000000000000000000000000000000000000000000815260040161028b90612041565b60405180910390fd5b60008989905067ffffffffffffffff8111156102b3576102b2612061565b5b6040519080825280602002602001820160405280156102e15781602001602082028036833780820191505090505b50905060005b8a8a9050811015610524576103238b8b8381811061030857610307612090565b5b905060200201602081019061031d9190611d7e565b336114d9565b82828151811061033657610335612090565b5b60200260200101818152505073eeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeee73ffffffffffffffffffffffffffffffffffffffff168b8b838181106103805761037f612090565b5b90506020020160208101906103959190611d7e565b73ffffffffffffffffffffffffffffffffffffffff16036103e457348282815181106103c4576103c3612090565b5b602002602001018181516103d891906120ee565b91508181525050610511565b60008b8b838181106103f9576103f8612090565b5b905060200201602081019061040e9190611d7e565b73ffffffffffffffffffffffffffffffffffffffff1663095ea7b360e01b8b7f800000000000000000000000000000000000000000000000000000000000000060405160240161045f929190612176565b604051602081830303815290604052907bffffffffffffffffffffffffffffffffffffffffffffffffffffffff19166020820180517bffffffffffffffffffffffffffffffffffffffffffffffffffffffff83818316178352505050506040516104c991906121db565b6000604051808303816000865af19150503d8060008114610506576040519150601f19603f3d011682016040523d82523d6000602084013e61050b565b606091505b50509050505b808061051c906121f2565b9150506102e7565b5060005a90508873ffffffffffffffffffffffffffffffffffffffff16888860405161055192919061226e565b600060405180830381855af49150503d806000811461058c576040519150601f19603f3d011682016040523d82523d6000602084013e610591565b606091505b5080945081955050505a816105a69190612287565b94508a8a905067ffffffffffffffff8111156105c5576105c4612061565b5b6040519080825280602002602001820160405280156105f35781602001602082028036833780820191505090505b50955060005b8b8b905081101561068c5782818151811061061757610616612090565b5b60200260200101516106508d8d8481811061063557610634612090565b5b905060200201602081019061064a9190611d7e565b336114d9565b61065a91906122bb565b87828151811061066d5761066c612090565b5b6020026020010181815250508080610684906121f2565b9150506105f9565b50505095509550955095915050565b60008273ffffffffffffffffffffffffffffffffffffffff1663dd62ed3e33846040518363ffffffff1660e01b81526004016106d89291906122fe565b602060405180830381865afa1580156106f5573d6000803e3d6000fd5b505050506040513d601f19601f82011682018060405250810190610719919061233c565b905092915050565b60608084849050878790501461076c576040517f08c379a0000000000000000000000000000000000000000000000000000000008152600401610763906123b5565b60405180910390fd5b8686905067ffffffffffffffff81111561078957610788612061565b5b6040519080825280602002602001820160405280156107b75781602001602082028036833780820191505090505b5091508686905067ffffffffffffffff8111156107d7576107d6612061565b5b60405190808252806020026020018201604052801561080a57816020015b60608152602001906001900390816107f55790505b50905060005b878790508110156109235787878281811061082e5761082d612090565b5b90506020020160208101906108439190611d7e565b73ffffffffffffffffffffffffffffffffffffffff168487878481811061086d5761086c612090565b5b905060200281019061087f91906123e4565b60405161088d92919061226e565b6000604051808303818686fa925050503d80600081146108c9576040519150601f19603f3d011682016040523d82523d6000602084013e6108ce565b606091505b508483815181106108e2576108e1612090565b5b602002602001018484815181106108fc576108fb612090565b5b6020026020010182905282151515158152505050808061091b906121f2565b915050610810565b509550959350505050565b600061093a82336114d9565b9050919050565b6000808390508073ffffffffffffffffffffffffffffffffffffffff1663095ea7b3847f80000000000000000000000000000000000000000000000000000000000000006040518363ffffffff1660e01b81526004016109a2929190612176565b6020604051808303816000875af11580156109c1573d6000803e3d6000fd5b505050506040513d601f19601f820116820180604052508101906109e59190612473565b50600091505092915050565b60008060008060006060610a0589306114d9565b9550610a1189896114d9565b93508873ffffffffffffffffffffffffffffffffffffffff1663a9059cbb60e01b8989604051602401610a459291906124a0565b604051602081830303815290604052907bffffffffffffffffffffffffffffffffffffffffffffffffffffffff19166020820180517bffffffffffffffffffffffffffffffffffffffffffffffffffffffff8381831617835250505050604051610aaf91906121db565b6000604051808303816000865af19150503d8060008114610aec576040519150601f19603f3d011682016040523d82523d6000602084013e610af1565b606091505b508092508193505050818015610b08575060008151115b15610b245780806020019051810190610b219190612473565b91505b610b2e89306114d9565b9450610b3a89896114d9565b925093975093979195509350565b6000806000806060610b59886114b6565b610b98576040517f08c379a0000000000000000000000000000000000000000000000000000000008152600401610b8f90612041565b60405180910390fd5b6000610ba48b336114d9565b905073eeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeee73ffffffffffffffffffffffffffffffffffffffff168b73ffffffffffffffffffffffffffffffffffffffff1603610bfc573481610bf991906120ee565b90505b6000610c088b336114d9565b905073eeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeee73ffffffffffffffffffffffffffffffffffffffff168c73ffffffffffffffffffffffffffffffffffffffff1614610cd1573073ffffffffffffffffffffffffffffffffffffffff16637e5465ba8d8c6040518363ffffffff1660e01b8152600401610c8c9291906122fe565b6020604051808303816000875af1158015610cab573d6000803e3d6000fd5b505050506040513d601f19601f82011682018060405250810190610ccf919061233c565b505b60005a90508a73ffffffffffffffffffffffffffffffffffffffff168a8a604051610cfd92919061226e565b600060405180830381855af49150503d8060008114610d38576040519150601f19603f3d011682016040523d82523d6000602084013e610d3d565b606091505b5080955081965050505a81610d529190612287565b95506000610d608e336114d9565b90506000610d6e8e336114d9565b90508185610d7c9190612287565b99508381610d8a9190612287565b985050505050509550955095509550959050565b600080600080600080606060008990506000600267ffffffffffffffff811115610dcb57610dca612061565b5b604051908082528060200260200182016040528015610df95781602001602082028036833780820191505090505b5090508173ffffffffffffffffffffffffffffffffffffffff1663ad5c46486040518163ffffffff1660e01b8152600401602060405180830381865afa158015610e47573d6000803e3d6000fd5b505050506040513d601f19601f82011682018060405250810190610e6b91906124de565b81600081518110610e7f57610e7e612090565b5b602002602001019073ffffffffffffffffffffffffffffffffffffffff16908173ffffffffffffffffffffffffffffffffffffffff16815250508981600181518110610ece57610ecd612090565b5b602002602001019073ffffffffffffffffffffffffffffffffffffffff16908173ffffffffffffffffffffffffffffffffffffffff16815250508173ffffffffffffffffffffffffffffffffffffffff1663d06ca61f34836040518363ffffffff1660e01b8152600401610f439291906125c9565b600060405180830381865afa158015610f60573d6000803e3d6000fd5b505050506040513d6000823e3d601f19601f82011682018060405250810190610f899190612708565b600181518110610f9c57610f9b612090565b5b602002602001015197508173ffffffffffffffffffffffffffffffffffffffff1663b6f9de953460008430426040518663ffffffff1660e01b8152600401610fe7949392919061278c565b6000604051808303818588803b15801561100057600080fd5b505af1158015611014573d6000803e3d6000fd5b50505050508973ffffffffffffffffffffffffffffffffffffffff166370a08231306040518263ffffffff1660e01b815260040161105291906127d8565b602060405180830381865afa15801561106f573d6000803e3d6000fd5b505050506040513d601f19601f82011682018060405250810190611093919061233c565b98506111368a63a9059cbb60e01b73111111111111111111111111111111111111110260648d6110c39190612822565b6040516024016110d49291906124a0565b604051602081830303815290604052907bffffffffffffffffffffffffffffffffffffffffffffffffffffffff19166020820180517bffffffffffffffffffffffffffffffffffffffffffffffffffffffff83818316178352505050506115c4565b965060008a73ffffffffffffffffffffffffffffffffffffffff166370a08231306040518263ffffffff1660e01b815260040161117391906127d8565b602060405180830381865afa158015611190573d6000803e3d6000fd5b505050506040513d601f19601f820116820180604052508101906111b4919061233c565b90508a826000815181106111cb576111ca612090565b5b602002602001019073ffffffffffffffffffffffffffffffffffffffff16908173ffffffffffffffffffffffffffffffffffffffff16815250508273ffffffffffffffffffffffffffffffffffffffff1663ad5c46486040518163ffffffff1660e01b8152600401602060405180830381865afa158015611250573d6000803e3d6000fd5b505050506040513d601f19601f8201168201806040525081019061127491906124de565b8260018151811061128857611287612090565b5b602002602001019073ffffffffffffffffffffffffffffffffffffffff16908173ffffffffffffffffffffffffffffffffffffffff16815250508273ffffffffffffffffffffffffffffffffffffffff1663d06ca61f82846040518363ffffffff1660e01b81526004016112fd9291906125c9565b600060405180830381865afa15801561131a573d6000803e3d6000fd5b505050506040513d6000823e3d601f19601f820116820180604052508101906113439190612708565b60018151811061135657611355612090565b5b602002602001015194506113e18b63095ea7b360e01b8e8460405160240161137f9291906124a0565b604051602081830303815290604052907bffffffffffffffffffffffffffffffffffffffffffffffffffffffff19166020820180517bffffffffffffffffffffffffffffffffffffffffffffffffffffffff83818316178352505050506115c4565b5060004790508373ffffffffffffffffffffffffffffffffffffffff1663791ac9478360008630426040518663ffffffff1660e01b8152600401611429959493929190612853565b600060405180830381600087803b15801561144357600080fd5b505af1925050508015611454575060015b611493573d8060008114611484576040519150601f19603f3d011682016040523d82523d6000602084013e611489565b606091505b50809550506114a6565b6001975080476114a39190612287565b96505b5050505092959891949750929550565b6000808273ffffffffffffffffffffffffffffffffffffffff163b119050919050565b600073eeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeee73ffffffffffffffffffffffffffffffffffffffff168373ffffffffffffffffffffffffffffffffffffffff1603611541578173ffffffffffffffffffffffffffffffffffffffff163190506115be565b8273ffffffffffffffffffffffffffffffffffffffff166370a08231836040518263ffffffff1660e01b815260040161157a91906127d8565b602060405180830381865afa158015611597573d6000803e3d6000fd5b505050506040513d601f19601f820116820180604052508101906115bb919061233c565b90505b92915050565b60008060008473ffffffffffffffffffffffffffffffffffffffff16846040516115ee91906121db565b6000604051808303816000865af19150503d806000811461162b576040519150601f19603f3d011682016040523d82523d6000602084013e611630565b606091505b5091509150818015611643575060008151115b1561165f578080602001905181019061165c9190612473565b91505b819250505092915050565b6000604051905090565b600080fd5b600080fd5b600080fd5b600080fd5b600080fd5b60008083601f8401126116a3576116a261167e565b5b8235905067ffffffffffffffff8111156116c0576116bf611683565b5b6020830191508360208202830111156116dc576116db611688565b5b9250929050565b600073ffffffffffffffffffffffffffffffffffffffff82169050919050565b600061170e826116e3565b9050919050565b61171e81611703565b811461172957600080fd5b50565b60008135905061173b81611715565b92915050565b60008083601f8401126117575761175661167e565b5b8235905067ffffffffffffffff81111561177457611773611683565b5b6020830191508360018202830111156117905761178f611688565b5b9250929050565b6000806000806000606086880312156117b3576117b2611674565b5b600086013567ffffffffffffffff8111156117d1576117d0611679565b5b6117dd8882890161168d565b955095505060206117f08882890161172c565b935050604086013567ffffffffffffffff81111561181157611810611679565b5b61181d88828901611741565b92509250509295509295909350565b600081519050919050565b600082825260208201905092915050565b6000819050602082019050919050565b6000819050919050565b61186b81611858565b82525050565b600061187d8383611862565b60208301905092915050565b6000602082019050919050565b60006118a18261182c565b6118ab8185611837565b93506118b683611848565b8060005b838110156118e75781516118ce8882611871565b97506118d983611889565b9250506001810190506118ba565b5085935050505092915050565b6000819050919050565b611907816118f4565b82525050565b60008115159050919050565b6119228161190d565b82525050565b600081519050919050565b600082825260208201905092915050565b60005b83811015611962578082015181840152602081019050611947565b60008484015250505050565b6000601f19601f8301169050919050565b600061198a82611928565b6119948185611933565b93506119a4818560208601611944565b6119ad8161196e565b840191505092915050565b600060808201905081810360008301526119d28187611896565b90506119e160208301866118fe565b6119ee6040830185611919565b8181036060830152611a00818461197f565b905095945050505050565b60008060408385031215611a2257611a21611674565b5b6000611a308582860161172c565b9250506020611a418582860161172c565b9150509250929050565b6000602082019050611a6060008301846118fe565b92915050565b60008083601f840112611a7c57611a7b61167e565b5b8235905067ffffffffffffffff811115611a9957611a98611683565b5b602083019150836020820283011115611ab557611ab4611688565b5b9250929050565b611ac5816118f4565b8114611ad057600080fd5b50565b600081359050611ae281611abc565b92915050565b600080600080600060608688031215611b0457611b03611674565b5b600086013567ffffffffffffffff811115611b2257611b21611679565b5b611b2e8882890161168d565b9550955050602086013567ffffffffffffffff811115611b5157611b50611679565b5b611b5d88828901611a66565b93509350506040611b7088828901611ad3565b9150509295509295909350565b600081519050919050565b600082825260208201905092915050565b6000819050602082019050919050565b611bb28161190d565b82525050565b6000611bc48383611ba9565b60208301905092915050565b6000602082019050919050565b6000611be882611b7d565b611bf28185611b88565b9350611bfd83611b99565b8060005b83811015611c2e578151611c158882611bb8565b9750611c2083611bd0565b925050600181019050611c01565b5085935050505092915050565b600081519050919050565b600082825260208201905092915050565b6000819050602082019050919050565b600082825260208201905092915050565b6000611c8382611928565b611c8d8185611c67565b9350611c9d818560208601611944565b611ca68161196e565b840191505092915050565b6000611cbd8383611c78565b905092915050565b6000602082019050919050565b6000611cdd82611c3b565b611ce78185611c46565b935083602082028501611cf985611c57565b8060005b85811015611d355784840389528151611d168582611cb1565b9450611d2183611cc5565b925060208a01995050600181019050611cfd565b50829750879550505050505092915050565b60006040820190508181036000830152611d618185611bdd565b90508181036020830152611d758184611cd2565b90509392505050565b600060208284031215611d9457611d93611674565b5b6000611da28482850161172c565b91505092915050565b600080600060608486031215611dc457611dc3611674565b5b6000611dd28682870161172c565b9350506020611de38682870161172c565b9250506040611df486828701611ad3565b9150509250925092565b600060c082019050611e1360008301896118fe565b611e2060208301886118fe565b611e2d60408301876118fe565b611e3a60608301866118fe565b611e476080830185611919565b81810360a0830152611e59818461197f565b9050979650505050505050565b600080600080600060808688031215611e8257611e81611674565b5b6000611e908882890161172c565b9550506020611ea18882890161172c565b9450506040611eb28882890161172c565b935050606086013567ffffffffffffffff811115611ed357611ed2611679565b5b611edf88828901611741565b92509250509295509295909350565b600060a082019050611f0360008301886118fe565b611f1060208301876118fe565b611f1d60408301866118fe565b611f2a6060830185611919565b8181036080830152611f3c818461197f565b90509695505050505050565b600060e082019050611f5d600083018a6118fe565b611f6a60208301896118fe565b611f776040830188611919565b611f846060830187611919565b611f9160808301866118fe565b611f9e60a08301856118fe565b81810360c0830152611fb0818461197f565b905098975050505050505050565b600082825260208201905092915050565b7f53696d537761703a20726f75746572206973206e6f74206120636f6e7472616360008201527f7400000000000000000000000000000000000000000000000000000000000000602082015250565b600061202b602183611fbe565b915061203682611fcf565b604082019050919050565b6000602082019050818103600083015261205a8161201e565b9050919050565b7f4e487b7100000000000000000000000000000000000000000000000000000000600052604160045260246000fd5b7f4e487b7100000000000000000000000000000000000000000000000000000000600052603260045260246000fd5b7f4e487b7100000000000000000000000000000000000000000000000000000000600052601160045260246000fd5b60006120f9826118f4565b9150612104836118f4565b925082820190508082111561211c5761211b6120bf565b5b92915050565b61212b81611703565b82525050565b6000819050919050565b6000819050919050565b600061216061215b61215684612131565b61213b565b6118f4565b9050919050565b61217081612145565b82525050565b600060408201905061218b6000830185612122565b6121986020830184612167565b9392505050565b600081905092915050565b60006121b582611928565b6121bf818561219f565b93506121cf818560208601611944565b80840191505092915050565b60006121e782846121aa565b915081905092915050565b60006121fd826118f4565b91507fffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff820361222f5761222e6120bf565b5b600182019050919050565b82818337600083830152505050565b6000612255838561219f565b935061226283858461223a565b82840190509392505050565b600061227b828486612249565b91508190509392505050565b6000612292826118f4565b915061229d836118f4565b92508282039050818111156122b5576122b46120bf565b5b92915050565b60006122c682611858565b91506122d183611858565b92508282039050818112600084121682821360008512151617156122f8576122f76120bf565b5b92915050565b60006040820190506123136000830185612122565b6123206020830184612122565b9392505050565b60008151905061233681611abc565b92915050565b60006020828403121561235257612351611674565b5b600061236084828501612327565b91505092915050565b7f53696d537761703a206c656e677468206d69736d617463680000000000000000600082015250565b600061239f601883611fbe565b91506123aa82612369565b602082019050919050565b600060208201905081810360008301526123ce81612392565b9050919050565b600080fd5b600080fd5b600080fd5b60008083356001602003843603038112612401576124006123d5565b5b80840192508235915067ffffffffffffffff821115612423576124226123da565b5b60208301925060018202360383131561243f5761243e6123df565b5b509250929050565b6124508161190d565b811461245b57600080fd5b50565b60008151905061246d81612447565b92915050565b60006020828403121561248957612488611674565b5b60006124978482850161245e565b91505092915050565b60006040820190506124b56000830185612122565b6124c260208301846118fe565b9392505050565b6000815190506124d881611715565b92915050565b6000602082840312156124f4576124f3611674565b5b6000612502848285016124c9565b91505092915050565b600081519050919050565b600082825260208201905092915050565b6000819050602082019050919050565b61254081611703565b82525050565b60006125528383612537565b60208301905092915050565b6000602082019050919050565b60006125768261250b565b6125808185612516565b935061258b83612527565b8060005b838110156125bc5781516125a38882612546565b97506125ae8361255e565b92505060018101905061258f565b5085935050505092915050565b60006040820190506125de60008301856118fe565b81810360208301526125f0818461256b565b90509392505050565b6126028261196e565b810181811067ffffffffffffffff8211171561262157612620612061565b5b80604052505050565b600061263461166a565b905061264082826125f9565b919050565b600067ffffffffffffffff8211156126605761265f612061565b5b602082029050602081019050919050565b600061268461267f84612645565b61262a565b905080838252602082019050602084028301858111156126a7576126a6611688565b5b835b818110156126d057806126bc8882612327565b8452602084019350506020810190506126a9565b5050509392505050565b600082601f8301126126ef576126ee61167e565b5b81516126ff848260208601612671565b91505092915050565b60006020828403121561271e5761271d611674565b5b600082015167ffffffffffffffff81111561273c5761273b611679565b5b612748848285016126da565b91505092915050565b6000819050919050565b600061277661277161276c84612751565b61213b565b6118f4565b9050919050565b6127868161275b565b82525050565b60006080820190506127a1600083018761277d565b81810360208301526127b3818661256b565b90506127c26040830185612122565b6127cf60608301846118fe565b95945050505050565b60006020820190506127ed6000830184612122565b92915050565b7f4e487b7100000000000000000000000000000000000000000000000000000000600052601260045260246000fd5b600061282d826118f4565b9150612838836118f4565b925082612848576128476127f3565b5b828204905092915050565b600060a08201905061286860008301886118fe565b612875602083018761277d565b8181036040830152612887818661256b565b90506128966060830185612122565b6128a360808301846118fe565b969550505050505056fea2646970667358221220718e56bd19ff2e5942f8a072cfa62607b4df8309d640ca5a28856269ad9e164364736f6c63430008150033"
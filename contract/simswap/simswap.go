@@ -30,7 +30,7 @@ var (
 
 // ContractMetaData contains all meta data concerning the Contract contract.
 var ContractMetaData = &bind.MetaData{
-	ABI: "[{\"inputs\":[{\"internalType\":\"address\",\"name\":\"token\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"spender\",\"type\":\"address\"}],\"name\":\"approve\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"token\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"router\",\"type\":\"address\"}],\"name\":\"getallowance\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"token1Diff\",\"type\":\"uint256\"}],\"stateMutability\":\"payable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"tokenIn\",\"type\":\"address\"}],\"name\":\"getbalance\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"token1Diff\",\"type\":\"uint256\"}],\"stateMutability\":\"payable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"tokenIn\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"tokenOut\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"router\",\"type\":\"address\"},{\"internalType\":\"bytes\",\"name\":\"data\",\"type\":\"bytes\"}],\"name\":\"simswap\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"token1Diff\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"token2Diff\",\"type\":\"uint256\"}],\"stateMutability\":\"payable\",\"type\":\"function\"}]",
+	ABI: "[{\"inputs\":[{\"internalType\":\"address\",\"name\":\"token\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"spender\",\"type\":\"address\"}],\"name\":\"approve\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"token\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"router\",\"type\":\"address\"}],\"name\":\"getallowance\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"token1Diff\",\"type\":\"uint256\"}],\"stateMutability\":\"payable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"tokenIn\",\"type\":\"address\"}],\"name\":\"getbalance\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"token1Diff\",\"type\":\"uint256\"}],\"stateMutability\":\"payable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"router\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"token\",\"type\":\"address\"}],\"name\":\"honeypot\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"bought\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"buyExpected\",\"type\":\"uint256\"},{\"internalType\":\"bool\",\"name\":\"transferOk\",\"type\":\"bool\"},{\"internalType\":\"bool\",\"name\":\"sellOk\",\"type\":\"bool\"},{\"internalType\":\"uint256\",\"name\":\"sold\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"sellExpected\",\"type\":\"uint256\"},{\"internalType\":\"bytes\",\"name\":\"sellRevert\",\"type\":\"bytes\"}],\"stateMutability\":\"payable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address[]\",\"name\":\"targets\",\"type\":\"address[]\"},{\"internalType\":\"bytes[]\",\"name\":\"data\",\"type\":\"bytes[]\"},{\"internalType\":\"uint256\",\"name\":\"gasPerCall\",\"type\":\"uint256\"}],\"name\":\"multicall\",\"outputs\":[{\"internalType\":\"bool[]\",\"name\":\"success\",\"type\":\"bool[]\"},{\"internalType\":\"bytes[]\",\"name\":\"results\",\"type\":\"bytes[]\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"tokenIn\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"tokenOut\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"router\",\"type\":\"address\"},{\"internalType\":\"bytes\",\"name\":\"data\",\"type\":\"bytes\"}],\"name\":\"simswap\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"token1Diff\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"token2Diff\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"gasUsed\",\"type\":\"uint256\"},{\"internalType\":\"bool\",\"name\":\"success\",\"type\":\"bool\"},{\"internalType\":\"bytes\",\"name\":\"returnData\",\"type\":\"bytes\"}],\"stateMutability\":\"payable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address[]\",\"name\":\"tokens\",\"type\":\"address[]\"},{\"internalType\":\"address\",\"name\":\"router\",\"type\":\"address\"},{\"internalType\":\"bytes\",\"name\":\"data\",\"type\":\"bytes\"}],\"name\":\"simswapMulti\",\"outputs\":[{\"internalType\":\"int256[]\",\"name\":\"deltas\",\"type\":\"int256[]\"},{\"internalType\":\"uint256\",\"name\":\"gasUsed\",\"type\":\"uint256\"},{\"internalType\":\"bool\",\"name\":\"success\",\"type\":\"bool\"},{\"internalType\":\"bytes\",\"name\":\"returnData\",\"type\":\"bytes\"}],\"stateMutability\":\"payable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"token\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"to\",\"type\":\"address\"},{\"internalType\":\"uint256\",\"name\":\"amount\",\"type\":\"uint256\"}],\"name\":\"transfercheck\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"fromBefore\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"fromAfter\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"toBefore\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"toAfter\",\"type\":\"uint256\"},{\"internalType\":\"bool\",\"name\":\"success\",\"type\":\"bool\"},{\"internalType\":\"bytes\",\"name\":\"returnData\",\"type\":\"bytes\"}],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"stateMutability\":\"payable\",\"type\":\"receive\"}]",
 }
 
 // ContractABI is the input ABI used to generate the binding from.
@@ -179,6 +179,51 @@ func (_Contract *ContractTransactorRaw) Transact(opts *bind.TransactOpts, method
 	return _Contract.Contract.contract.Transact(opts, method, params...)
 }
 
+// Multicall is a free data retrieval call binding the contract method 0x5342940b.
+//
+// Solidity: function multicall(address[] targets, bytes[] data, uint256 gasPerCall) view returns(bool[] success, bytes[] results)
+func (_Contract *ContractCaller) Multicall(opts *bind.CallOpts, targets []common.Address, data [][]byte, gasPerCall *big.Int) (struct {
+	Success []bool
+	Results [][]byte
+}, error) {
+	var out []interface{}
+	err := _Contract.contract.Call(opts, &out, "multicall", targets, data, gasPerCall)
+
+	outstruct := new(struct {
+		Success []bool
+		Results [][]byte
+	})
+	if err != nil {
+		return *outstruct, err
+	}
+
+	outstruct.Success = *abi.ConvertType(out[0], new([]bool)).(*[]bool)
+	outstruct.Results = *abi.ConvertType(out[1], new([][]byte)).(*[][]byte)
+
+	return *outstruct, err
+
+}
+
+// Multicall is a free data retrieval call binding the contract method 0x5342940b.
+//
+// Solidity: function multicall(address[] targets, bytes[] data, uint256 gasPerCall) view returns(bool[] success, bytes[] results)
+func (_Contract *ContractSession) Multicall(targets []common.Address, data [][]byte, gasPerCall *big.Int) (struct {
+	Success []bool
+	Results [][]byte
+}, error) {
+	return _Contract.Contract.Multicall(&_Contract.CallOpts, targets, data, gasPerCall)
+}
+
+// Multicall is a free data retrieval call binding the contract method 0x5342940b.
+//
+// Solidity: function multicall(address[] targets, bytes[] data, uint256 gasPerCall) view returns(bool[] success, bytes[] results)
+func (_Contract *ContractCallerSession) Multicall(targets []common.Address, data [][]byte, gasPerCall *big.Int) (struct {
+	Success []bool
+	Results [][]byte
+}, error) {
+	return _Contract.Contract.Multicall(&_Contract.CallOpts, targets, data, gasPerCall)
+}
+
 // Approve is a paid mutator transaction binding the contract method 0x7e5465ba.
 //
 // Solidity: function approve(address token, address spender) returns(uint256)
@@ -242,23 +287,107 @@ func (_Contract *ContractTransactorSession) Getbalance(tokenIn common.Address) (
 	return _Contract.Contract.Getbalance(&_Contract.TransactOpts, tokenIn)
 }
 
+// Honeypot is a paid mutator transaction binding the contract method 0xad5b26ee.
+//
+// Solidity: function honeypot(address router, address token) payable returns(uint256 bought, uint256 buyExpected, bool transferOk, bool sellOk, uint256 sold, uint256 sellExpected, bytes sellRevert)
+func (_Contract *ContractTransactor) Honeypot(opts *bind.TransactOpts, router common.Address, token common.Address) (*types.Transaction, error) {
+	return _Contract.contract.Transact(opts, "honeypot", router, token)
+}
+
+// Honeypot is a paid mutator transaction binding the contract method 0xad5b26ee.
+//
+// Solidity: function honeypot(address router, address token) payable returns(uint256 bought, uint256 buyExpected, bool transferOk, bool sellOk, uint256 sold, uint256 sellExpected, bytes sellRevert)
+func (_Contract *ContractSession) Honeypot(router common.Address, token common.Address) (*types.Transaction, error) {
+	return _Contract.Contract.Honeypot(&_Contract.TransactOpts, router, token)
+}
+
+// Honeypot is a paid mutator transaction binding the contract method 0xad5b26ee.
+//
+// Solidity: function honeypot(address router, address token) payable returns(uint256 bought, uint256 buyExpected, bool transferOk, bool sellOk, uint256 sold, uint256 sellExpected, bytes sellRevert)
+func (_Contract *ContractTransactorSession) Honeypot(router common.Address, token common.Address) (*types.Transaction, error) {
+	return _Contract.Contract.Honeypot(&_Contract.TransactOpts, router, token)
+}
+
 // Simswap is a paid mutator transaction binding the contract method 0x96d27420.
 //
-// Solidity: function simswap(address tokenIn, address tokenOut, address router, bytes data) payable returns(uint256 token1Diff, uint256 token2Diff)
+// Solidity: function simswap(address tokenIn, address tokenOut, address router, bytes data) payable returns(uint256 token1Diff, uint256 token2Diff, uint256 gasUsed, bool success, bytes returnData)
 func (_Contract *ContractTransactor) Simswap(opts *bind.TransactOpts, tokenIn common.Address, tokenOut common.Address, router common.Address, data []byte) (*types.Transaction, error) {
 	return _Contract.contract.Transact(opts, "simswap", tokenIn, tokenOut, router, data)
 }
 
 // Simswap is a paid mutator transaction binding the contract method 0x96d27420.
 //
-// Solidity: function simswap(address tokenIn, address tokenOut, address router, bytes data) payable returns(uint256 token1Diff, uint256 token2Diff)
+// Solidity: function simswap(address tokenIn, address tokenOut, address router, bytes data) payable returns(uint256 token1Diff, uint256 token2Diff, uint256 gasUsed, bool success, bytes returnData)
 func (_Contract *ContractSession) Simswap(tokenIn common.Address, tokenOut common.Address, router common.Address, data []byte) (*types.Transaction, error) {
 	return _Contract.Contract.Simswap(&_Contract.TransactOpts, tokenIn, tokenOut, router, data)
 }
 
 // Simswap is a paid mutator transaction binding the contract method 0x96d27420.
 //
-// Solidity: function simswap(address tokenIn, address tokenOut, address router, bytes data) payable returns(uint256 token1Diff, uint256 token2Diff)
+// Solidity: function simswap(address tokenIn, address tokenOut, address router, bytes data) payable returns(uint256 token1Diff, uint256 token2Diff, uint256 gasUsed, bool success, bytes returnData)
 func (_Contract *ContractTransactorSession) Simswap(tokenIn common.Address, tokenOut common.Address, router common.Address, data []byte) (*types.Transaction, error) {
 	return _Contract.Contract.Simswap(&_Contract.TransactOpts, tokenIn, tokenOut, router, data)
 }
+
+// SimswapMulti is a paid mutator transaction binding the contract method 0x02ad2776.
+//
+// Solidity: function simswapMulti(address[] tokens, address router, bytes data) payable returns(int256[] deltas, uint256 gasUsed, bool success, bytes returnData)
+func (_Contract *ContractTransactor) SimswapMulti(opts *bind.TransactOpts, tokens []common.Address, router common.Address, data []byte) (*types.Transaction, error) {
+	return _Contract.contract.Transact(opts, "simswapMulti", tokens, router, data)
+}
+
+// SimswapMulti is a paid mutator transaction binding the contract method 0x02ad2776.
+//
+// Solidity: function simswapMulti(address[] tokens, address router, bytes data) payable returns(int256[] deltas, uint256 gasUsed, bool success, bytes returnData)
+func (_Contract *ContractSession) SimswapMulti(tokens []common.Address, router common.Address, data []byte) (*types.Transaction, error) {
+	return _Contract.Contract.SimswapMulti(&_Contract.TransactOpts, tokens, router, data)
+}
+
+// SimswapMulti is a paid mutator transaction binding the contract method 0x02ad2776.
+//
+// Solidity: function simswapMulti(address[] tokens, address router, bytes data) payable returns(int256[] deltas, uint256 gasUsed, bool success, bytes returnData)
+func (_Contract *ContractTransactorSession) SimswapMulti(tokens []common.Address, router common.Address, data []byte) (*types.Transaction, error) {
+	return _Contract.Contract.SimswapMulti(&_Contract.TransactOpts, tokens, router, data)
+}
+
+// Transfercheck is a paid mutator transaction binding the contract method 0x89d83497.
+//
+// Solidity: function transfercheck(address token, address to, uint256 amount) returns(uint256 fromBefore, uint256 fromAfter, uint256 toBefore, uint256 toAfter, bool success, bytes returnData)
+func (_Contract *ContractTransactor) Transfercheck(opts *bind.TransactOpts, token common.Address, to common.Address, amount *big.Int) (*types.Transaction, error) {
+	return _Contract.contract.Transact(opts, "transfercheck", token, to, amount)
+}
+
+// Transfercheck is a paid mutator transaction binding the contract method 0x89d83497.
+//
+// Solidity: function transfercheck(address token, address to, uint256 amount) returns(uint256 fromBefore, uint256 fromAfter, uint256 toBefore, uint256 toAfter, bool success, bytes returnData)
+func (_Contract *ContractSession) Transfercheck(token common.Address, to common.Address, amount *big.Int) (*types.Transaction, error) {
+	return _Contract.Contract.Transfercheck(&_Contract.TransactOpts, token, to, amount)
+}
+
+// Transfercheck is a paid mutator transaction binding the contract method 0x89d83497.
+//
+// Solidity: function transfercheck(address token, address to, uint256 amount) returns(uint256 fromBefore, uint256 fromAfter, uint256 toBefore, uint256 toAfter, bool success, bytes returnData)
+func (_Contract *ContractTransactorSession) Transfercheck(token common.Address, to common.Address, amount *big.Int) (*types.Transaction, error) {
+	return _Contract.Contract.Transfercheck(&_Contract.TransactOpts, token, to, amount)
+}
+
+// Receive is a paid mutator transaction binding the contract receive function.
+//
+// Solidity: receive() payable returns()
+func (_Contract *ContractTransactor) Receive(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _Contract.contract.RawTransact(opts, nil) // calldata is disallowed for receive function
+}
+
+// Receive is a paid mutator transaction binding the contract receive function.
+//
+// Solidity: receive() payable returns()
+func (_Contract *ContractSession) Receive() (*types.Transaction, error) {
+	return _Contract.Contract.Receive(&_Contract.TransactOpts)
+}
+
+// Receive is a paid mutator transaction binding the contract receive function.
+//
+// Solidity: receive() payable returns()
+func (_Contract *ContractTransactorSession) Receive() (*types.Transaction, error) {
+	return _Contract.Contract.Receive(&_Contract.TransactOpts)
+}
@@ -6,6 +6,7 @@ replace github.com/ethereum/go-ethereum v1.10.20 => /Users/nguyenducminh/Lotus/P
 
 require (
 	github.com/ethereum/go-ethereum v1.10.20
+	github.com/gorilla/websocket v1.4.2
 	github.com/pkg/errors v0.9.1
 )
 
@@ -17,7 +18,6 @@ require (
 	github.com/go-ole/go-ole v1.2.1 // indirect
 	github.com/go-stack/stack v1.8.0 // indirect
 	github.com/google/uuid v1.2.0 // indirect
-	github.com/gorilla/websocket v1.4.2 // indirect
 	github.com/miguelmota/go-solidity-sha3 v0.1.1 // indirect
 	github.com/rjeczalik/notify v0.9.1 // indirect
 	github.com/shirou/gopsutil v3.21.4-0.20210419000835-c7a38de76ee5+incompatible // indirect
@@ -0,0 +1,200 @@
+package rpccache
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/ethereum/go-ethereum/rpc"
+	"sync/atomic"
+)
+
+// cachedMethods maps the methods whose results are cached to the index of
+// their block parameter.
+var cachedMethods = map[string]int{
+	"eth_getStorageAt": 2,
+	"eth_getCode":      1,
+	"eth_getBalance":   1,
+	"eth_call":         1,
+}
+
+// Client is the node connection a Cache wraps and implements.
+type Client interface {
+	CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error
+	BatchCallContext(ctx context.Context, b []rpc.BatchElem) error
+}
+
+// Backend stores results by key. Implementations must be safe for concurrent
+// use.
+type Backend interface {
+	Get(key string) ([]byte, bool)
+	Put(key string, value []byte)
+}
+
+// Metrics counts lookups of cacheable calls since the Cache was created.
+type Metrics struct {
+	Hits     uint64
+	DiskHits uint64
+	Misses   uint64
+}
+
+func (m Metrics) String() string {
+	return fmt.Sprintf("hits %d (disk %d) misses %d", m.Hits, m.DiskHits, m.Misses)
+}
+
+// Cache is a Client remembering the results of eth_getStorageAt,
+// eth_getCode, eth_getBalance and eth_call made at a block hash, which cannot
+// change. eth_call is keyed by all of its arguments, overrides included.
+// Calls by block number or tag, and errors, are not cached.
+//
+// A hit does not reach the node, so "requireCanonical" is only checked on a
+// miss: a simulation.Session over a Cache keeps answering from a block that
+// was reorged out. Session.CheckReorg tells.
+type Cache struct {
+	client Client
+	memory *LRU
+	disk   Backend
+
+	hits, diskHits, misses uint64
+}
+
+// New caches up to size results in memory and, if disk is not nil, all of
+// them on disk.
+func New(client Client, size int, disk Backend) *Cache {
+	return &Cache{client: client, memory: NewLRU(size), disk: disk}
+}
+
+// Metrics returns the lookup counts so far.
+func (c *Cache) Metrics() Metrics {
+	return Metrics{
+		Hits:     atomic.LoadUint64(&c.hits),
+		DiskHits: atomic.LoadUint64(&c.diskHits),
+		Misses:   atomic.LoadUint64(&c.misses),
+	}
+}
+
+func (c *Cache) CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error {
+	key, ok := cacheKey(method, args)
+	if !ok {
+		return c.client.CallContext(ctx, result, method, args...)
+	}
+	if value, ok := c.get(key); ok {
+		return unmarshal(value, result)
+	}
+	var value json.RawMessage
+	if err := c.client.CallContext(ctx, &value, method, args...); err != nil {
+		return err
+	}
+	c.put(key, value)
+	return unmarshal(value, result)
+}
+
+// BatchCallContext answers what it can of b from the cache and sends the rest
+// as one batch.
+func (c *Cache) BatchCallContext(ctx context.Context, b []rpc.BatchElem) error {
+	var (
+		misses  []rpc.BatchElem
+		indexes []int
+		keys    []string
+	)
+	for i, elem := range b {
+		key, ok := cacheKey(elem.Method, elem.Args)
+		if ok {
+			if value, ok := c.get(key); ok {
+				b[i].Error = unmarshal(value, elem.Result)
+				continue
+			}
+		}
+		miss := elem
+		if ok {
+			miss.Result = new(json.RawMessage)
+		}
+		misses = append(misses, miss)
+		indexes = append(indexes, i)
+		keys = append(keys, key)
+	}
+	if len(misses) == 0 {
+		return nil
+	}
+	if err := c.client.BatchCallContext(ctx, misses); err != nil {
+		return err
+	}
+	for j, miss := range misses {
+		i := indexes[j]
+		b[i].Error = miss.Error
+		if keys[j] == "" {
+			continue
+		}
+		value := *miss.Result.(*json.RawMessage)
+		if miss.Error == nil {
+			c.put(keys[j], value)
+			b[i].Error = unmarshal(value, b[i].Result)
+		}
+	}
+	return nil
+}
+
+func (c *Cache) get(key string) ([]byte, bool) {
+	if value, ok := c.memory.Get(key); ok {
+		atomic.AddUint64(&c.hits, 1)
+		return value, true
+	}
+	if c.disk != nil {
+		if value, ok := c.disk.Get(key); ok {
+			atomic.AddUint64(&c.hits, 1)
+			atomic.AddUint64(&c.diskHits, 1)
+			c.memory.Put(key, value)
+			return value, true
+		}
+	}
+	atomic.AddUint64(&c.misses, 1)
+	return nil, false
+}
+
+// put stores value unless it is null, which nodes also return for unknown
+// blocks.
+func (c *Cache) put(key string, value []byte) {
+	if len(value) == 0 || bytes.Equal(value, []byte("null")) {
+		return
+	}
+	c.memory.Put(key, value)
+	if c.disk != nil {
+		c.disk.Put(key, value)
+	}
+}
+
+// cacheKey is the key of a cacheable call: one of cachedMethods at a block
+// hash, given as an EIP-1898 object or a bare hash.
+func cacheKey(method string, args []interface{}) (string, bool) {
+	i, ok := cachedMethods[method]
+	if !ok || i >= len(args) {
+		return "", false
+	}
+	block, err := json.Marshal(args[i])
+	if err != nil || !isBlockHash(block) {
+		return "", false
+	}
+	data, err := json.Marshal(args)
+	if err != nil {
+		return "", false
+	}
+	return method + string(data), true
+}
+
+func isBlockHash(block []byte) bool {
+	var hash string
+	if json.Unmarshal(block, &hash) == nil {
+		return len(hash) == 66
+	}
+	var object struct {
+		BlockHash *string `json:"blockHash"`
+	}
+	return json.Unmarshal(block, &object) == nil && object.BlockHash != nil
+}
+
+func unmarshal(value []byte, result interface{}) error {
+	if result == nil {
+		return nil
+	}
+	return json.Unmarshal(value, result)
+}
@@ -0,0 +1,76 @@
+package rpcpool
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// tokenBucket allows rate requests per second on average and up to burst at
+// once.
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(rate float64, burst int) *tokenBucket {
+	if burst < 1 {
+		burst = int(math.Max(1, math.Ceil(rate)))
+	}
+	return &tokenBucket{rate: rate, burst: float64(burst), tokens: float64(burst), last: time.Now()}
+}
+
+// wait takes n tokens, at most a full bucket, sleeping until they are there
+// or ctx is done.
+func (b *tokenBucket) wait(ctx context.Context, n int) error {
+	need := math.Min(float64(n), b.burst)
+	for {
+		b.mu.Lock()
+		now := time.Now()
+		b.tokens = math.Min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+		b.last = now
+		if b.tokens >= need {
+			b.tokens -= need
+			b.mu.Unlock()
+			return nil
+		}
+		delay := time.Duration((need - b.tokens) / b.rate * float64(time.Second))
+		b.mu.Unlock()
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+}
+
+// acquire waits for e's rate limit and a free in-flight slot, for a request
+// of n calls. release must be called when the request is done.
+func (e *endpoint) acquire(ctx context.Context, n int) (release func(), err error) {
+	if e.bucket != nil {
+		if err := e.bucket.wait(ctx, n); err != nil {
+			return nil, err
+		}
+	}
+	if e.slots == nil {
+		return func() {}, nil
+	}
+	select {
+	case e.slots <- struct{}{}:
+		return func() { <-e.slots }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// busy reports whether all of e's in-flight slots are taken.
+func (e *endpoint) busy() bool {
+	return e.slots != nil && len(e.slots) == cap(e.slots)
+}
@@ -0,0 +1,361 @@
+package rpcpool
+
+import (
+	"context"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/pkg/errors"
+	"math"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Capability is a feature an endpoint may lack.
+type Capability uint
+
+const (
+	// Overrides is eth_call (and eth_estimateGas) with state overrides.
+	Overrides Capability = 1 << iota
+	// Trace is the debug namespace, debug_traceCall included.
+	Trace
+	// Archive is state older than the last ArchiveDepth blocks.
+	Archive
+)
+
+func (c Capability) String() string {
+	var names []string
+	for _, n := range []struct {
+		c    Capability
+		name string
+	}{{Overrides, "overrides"}, {Trace, "trace"}, {Archive, "archive"}} {
+		if c&n.c != 0 {
+			names = append(names, n.name)
+		}
+	}
+	return strings.Join(names, "+")
+}
+
+var (
+	// ArchiveDepth is how many recent blocks a full node keeps state for.
+	ArchiveDepth uint64 = 128
+	// HealthInterval is the time between health checks.
+	HealthInterval = 15 * time.Second
+	// FailureBackoff and RateLimitBackoff are how long an endpoint is
+	// skipped after failing or rate limiting a call.
+	FailureBackoff   = 30 * time.Second
+	RateLimitBackoff = 10 * time.Second
+
+	ErrNoEndpoint = errors.New("rpcpool: no endpoint supports the request")
+)
+
+// Endpoint is a node and what it supports. Weight spreads calls among the
+// endpoints able to serve them; zero counts as one.
+type Endpoint struct {
+	URL       string `json:"url"`
+	Weight    int    `json:"weight,omitempty"`
+	Overrides bool   `json:"overrides,omitempty"`
+	Trace     bool   `json:"trace,omitempty"`
+	Archive   bool   `json:"archive,omitempty"`
+	// MaxInFlight bounds the requests waiting on the endpoint at once, a
+	// batch counting as one. RateLimit is in calls per second, each call of
+	// a batch counting, with bursts of up to Burst calls. Zero means no
+	// limit.
+	MaxInFlight int     `json:"maxInFlight,omitempty"`
+	RateLimit   float64 `json:"rateLimit,omitempty"`
+	Burst       int     `json:"burst,omitempty"`
+}
+
+func (e *Endpoint) capabilities() Capability {
+	var c Capability
+	if e.Overrides {
+		c |= Overrides
+	}
+	if e.Trace {
+		c |= Trace
+	}
+	if e.Archive {
+		c |= Archive
+	}
+	return c
+}
+
+type endpoint struct {
+	Endpoint
+	client *rpc.Client
+	slots  chan struct{}
+	bucket *tokenBucket
+
+	mu        sync.Mutex
+	head      uint64
+	downUntil time.Time
+	lastErr   error
+	calls     uint64
+	failures  uint64
+}
+
+func (e *endpoint) usable(now time.Time) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return !now.Before(e.downUntil)
+}
+
+func (e *endpoint) fail(err error, backoff time.Duration) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.failures++
+	e.lastErr = err
+	e.downUntil = time.Now().Add(backoff)
+}
+
+// Status is a snapshot of an endpoint.
+type Status struct {
+	URL       string
+	Head      uint64
+	DownUntil time.Time
+	LastError error
+	Calls     uint64
+	Failures  uint64
+}
+
+// Pool spreads calls over endpoints, sending each only to endpoints with the
+// capabilities it needs and failing over to the next one on any error but
+// the call failing in the EVM, which is returned as it is. It implements
+// simulation.Client.
+type Pool struct {
+	endpoints []*endpoint
+	close     chan struct{}
+	wg        sync.WaitGroup
+
+	randMu sync.Mutex
+	rand   *rand.Rand
+}
+
+// Dial connects to endpoints, checks their health once and keeps checking it
+// every HealthInterval until Close.
+func Dial(ctx context.Context, endpoints []Endpoint) (*Pool, error) {
+	if len(endpoints) == 0 {
+		return nil, errors.New("rpcpool: no endpoints")
+	}
+	p := &Pool{
+		close: make(chan struct{}),
+		rand:  rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+	for _, config := range endpoints {
+		client, err := rpc.DialContext(ctx, config.URL)
+		if err != nil {
+			p.Close()
+			return nil, errors.WithMessagef(err, "rpcpool: dial %s", config.URL)
+		}
+		e := &endpoint{Endpoint: config, client: client}
+		if config.MaxInFlight > 0 {
+			e.slots = make(chan struct{}, config.MaxInFlight)
+		}
+		if config.RateLimit > 0 {
+			e.bucket = newTokenBucket(config.RateLimit, config.Burst)
+		}
+		p.endpoints = append(p.endpoints, e)
+	}
+	p.checkHealth(ctx)
+
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		ticker := time.NewTicker(HealthInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				ctx, cancel := context.WithTimeout(context.Background(), HealthInterval)
+				p.checkHealth(ctx)
+				cancel()
+			case <-p.close:
+				return
+			}
+		}
+	}()
+	return p, nil
+}
+
+func (p *Pool) Close() {
+	select {
+	case <-p.close:
+		return
+	default:
+		close(p.close)
+	}
+	p.wg.Wait()
+	for _, e := range p.endpoints {
+		e.client.Close()
+	}
+}
+
+// checkHealth reads every endpoint's head. An endpoint that fails is skipped
+// until the next check; a recovered one becomes usable when its backoff ends.
+func (p *Pool) checkHealth(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, e := range p.endpoints {
+		wg.Add(1)
+		go func(e *endpoint) {
+			defer wg.Done()
+			var head hexutil.Uint64
+			if err := e.client.CallContext(ctx, &head, "eth_blockNumber"); err != nil {
+				e.fail(err, HealthInterval)
+				return
+			}
+			e.mu.Lock()
+			e.head = uint64(head)
+			e.mu.Unlock()
+		}(e)
+	}
+	wg.Wait()
+}
+
+// Status reports the endpoints in configuration order.
+func (p *Pool) Status() []Status {
+	status := make([]Status, len(p.endpoints))
+	for i, e := range p.endpoints {
+		e.mu.Lock()
+		status[i] = Status{
+			URL:       e.URL,
+			Head:      e.head,
+			DownUntil: e.downUntil,
+			LastError: e.lastErr,
+			Calls:     e.calls,
+			Failures:  e.failures,
+		}
+		e.mu.Unlock()
+	}
+	return status
+}
+
+// head is the highest head seen among the endpoints.
+func (p *Pool) head() uint64 {
+	var head uint64
+	for _, e := range p.endpoints {
+		e.mu.Lock()
+		if e.head > head {
+			head = e.head
+		}
+		e.mu.Unlock()
+	}
+	return head
+}
+
+// candidates orders the endpoints having need by weighted random choice,
+// usable ones with a free in-flight slot first. Endpoints in backoff stay as
+// a last resort. The health checks bypass the limits.
+func (p *Pool) candidates(need Capability) []*endpoint {
+	type keyed struct {
+		e   *endpoint
+		key float64
+	}
+	now := time.Now()
+	var idle, busy, down []keyed
+	p.randMu.Lock()
+	for _, e := range p.endpoints {
+		if e.capabilities()&need != need {
+			continue
+		}
+		weight := e.Weight
+		if weight <= 0 {
+			weight = 1
+		}
+		// Efraimidis-Spirakis: sorting by -ln(u)/w samples by weight.
+		k := keyed{e, -math.Log(1-p.rand.Float64()) / float64(weight)}
+		switch {
+		case !e.usable(now):
+			down = append(down, k)
+		case e.busy():
+			busy = append(busy, k)
+		default:
+			idle = append(idle, k)
+		}
+	}
+	p.randMu.Unlock()
+
+	var ordered []*endpoint
+	for _, group := range [][]keyed{idle, busy, down} {
+		for len(group) > 0 {
+			best := 0
+			for i := range group {
+				if group[i].key < group[best].key {
+					best = i
+				}
+			}
+			ordered = append(ordered, group[best].e)
+			group = append(group[:best], group[best+1:]...)
+		}
+	}
+	return ordered
+}
+
+func (p *Pool) CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error {
+	need := requirements(method, args, p.head())
+	return p.try(ctx, need, method, 1, func(e *endpoint) error {
+		return e.client.CallContext(ctx, result, method, args...)
+	})
+}
+
+// BatchCallContext sends the batch to an endpoint supporting all of its
+// calls. Elements failing in the EVM are left in the batch; any other failing
+// element fails the batch over to the next endpoint.
+func (p *Pool) BatchCallContext(ctx context.Context, b []rpc.BatchElem) error {
+	head := p.head()
+	var need Capability
+	for _, elem := range b {
+		need |= requirements(elem.Method, elem.Args, head)
+	}
+	return p.try(ctx, need, "batch", len(b), func(e *endpoint) error {
+		for i := range b {
+			b[i].Error = nil
+		}
+		if err := e.client.BatchCallContext(ctx, b); err != nil {
+			return err
+		}
+		for _, elem := range b {
+			if elem.Error != nil && classify(elem.Error) != callError {
+				return elem.Error
+			}
+		}
+		return nil
+	})
+}
+
+// try makes a request of n calls on the candidates for need in turn, waiting
+// for each one's limits.
+func (p *Pool) try(ctx context.Context, need Capability, method string, n int, call func(*endpoint) error) error {
+	candidates := p.candidates(need)
+	if len(candidates) == 0 {
+		return errors.WithMessage(ErrNoEndpoint, fmt.Sprintf("%s needs %s", method, need))
+	}
+	var errs []string
+	for _, e := range candidates {
+		release, err := e.acquire(ctx, n)
+		if err != nil {
+			return err
+		}
+		e.mu.Lock()
+		e.calls++
+		e.mu.Unlock()
+		err = call(e)
+		release()
+		if err == nil || ctx.Err() != nil {
+			return err
+		}
+		switch classify(err) {
+		case callError:
+			return err
+		case refused:
+			// The endpoint is fine, only short of what this call needs.
+		case rateLimited:
+			e.fail(err, RateLimitBackoff)
+		default:
+			e.fail(err, FailureBackoff)
+		}
+		errs = append(errs, e.URL+": "+err.Error())
+	}
+	return errors.Errorf("rpcpool: %s failed on every endpoint: %s", method, strings.Join(errs, "; "))
+}
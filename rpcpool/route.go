@@ -0,0 +1,153 @@
+package rpcpool
+
+import (
+	"encoding/json"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/pkg/errors"
+	"strings"
+)
+
+// blockParam is the index of the block parameter of methods reading state.
+var blockParam = map[string]int{
+	"eth_call":                1,
+	"eth_estimateGas":         1,
+	"debug_traceCall":         1,
+	"eth_getBalance":          1,
+	"eth_getCode":             1,
+	"eth_getTransactionCount": 1,
+	"eth_getStorageAt":        2,
+	"eth_getProof":            2,
+}
+
+// requirements is what an endpoint needs to serve method with args, given
+// the chain head.
+func requirements(method string, args []interface{}, head uint64) Capability {
+	var need Capability
+	if strings.HasPrefix(method, "debug_") || strings.HasPrefix(method, "trace_") {
+		need |= Trace
+	}
+	if (method == "eth_call" || method == "eth_estimateGas") && len(args) > 2 && !isNull(args[2]) {
+		need |= Overrides
+	}
+	if i, ok := blockParam[method]; ok && i < len(args) && historic(args[i], head) {
+		need |= Archive
+	}
+	return need
+}
+
+// historic reports whether block is older than what a full node keeps state
+// for. Blocks given by hash are assumed recent.
+func historic(block interface{}, head uint64) bool {
+	data, err := json.Marshal(block)
+	if err != nil {
+		return false
+	}
+	var tag string
+	if json.Unmarshal(data, &tag) != nil {
+		return false
+	}
+	if tag == "earliest" {
+		return true
+	}
+	number, err := hexutil.DecodeUint64(tag)
+	if err != nil {
+		return false
+	}
+	return head > ArchiveDepth && number < head-ArchiveDepth
+}
+
+func isNull(arg interface{}) bool {
+	if arg == nil {
+		return true
+	}
+	data, err := json.Marshal(arg)
+	return err == nil && string(data) == "null"
+}
+
+type errorKind int
+
+const (
+	// unavailable is a transport or server failure, worth another endpoint.
+	unavailable errorKind = iota
+	// rateLimited is an endpoint refusing calls for a while.
+	rateLimited
+	// refused is an endpoint unable to serve this call, e.g. for lack of the
+	// block or the method, which another endpoint may have.
+	refused
+	// callError is the call failing in the EVM, the same on every endpoint.
+	callError
+)
+
+// limitExceeded is the JSON-RPC error code nodes and providers use for rate
+// limits.
+const limitExceeded = -32005
+
+func classify(err error) errorKind {
+	var httpErr rpc.HTTPError
+	if errors.As(err, &httpErr) {
+		if httpErr.StatusCode == 429 {
+			return rateLimited
+		}
+		return unavailable
+	}
+	var rpcErr rpc.Error
+	if errors.As(err, &rpcErr) {
+		if rpcErr.ErrorCode() == limitExceeded || strings.Contains(strings.ToLower(rpcErr.Error()), "rate limit") {
+			return rateLimited
+		}
+		if IsExecutionError(err) {
+			return callError
+		}
+		return refused
+	}
+	return unavailable
+}
+
+// executionErrors are the messages of the EVM failing a call, as opposed to
+// the node failing to run it.
+var executionErrors = []string{
+	vm.ErrExecutionReverted.Error(),
+	vm.ErrOutOfGas.Error(),
+	vm.ErrCodeStoreOutOfGas.Error(),
+	vm.ErrDepth.Error(),
+	vm.ErrInsufficientBalance.Error(),
+	vm.ErrInvalidJump.Error(),
+	vm.ErrWriteProtection.Error(),
+	vm.ErrReturnDataOutOfBounds.Error(),
+	vm.ErrGasUintOverflow.Error(),
+	core.ErrIntrinsicGas.Error(),
+	"invalid opcode",
+	"stack underflow",
+	"stack limit reached",
+}
+
+// IsExecutionError reports whether err is a node answering that the call
+// reverted or failed in the EVM. geth gives every JSON-RPC error a code and
+// data, so only code 3, hex revert data or an EVM error message count; any
+// other error is the node's, such as a missing block or a rate limit.
+func IsExecutionError(err error) bool {
+	var rpcErr rpc.Error
+	if !errors.As(err, &rpcErr) {
+		return false
+	}
+	if rpcErr.ErrorCode() == 3 {
+		return true
+	}
+	var dataErr rpc.DataError
+	if errors.As(err, &dataErr) {
+		if data, ok := dataErr.ErrorData().(string); ok {
+			if _, err := hexutil.Decode(data); err == nil {
+				return true
+			}
+		}
+	}
+	for _, msg := range executionErrors {
+		if strings.Contains(rpcErr.Error(), msg) {
+			return true
+		}
+	}
+	return false
+}
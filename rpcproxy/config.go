@@ -0,0 +1,46 @@
+package rpcproxy
+
+import (
+	"encoding/json"
+	"geth/simulation"
+	"github.com/pkg/errors"
+	"io/ioutil"
+)
+
+// DefaultProfileHeader selects a profile by name, taking precedence over the
+// API key in the URL path.
+const DefaultProfileHeader = "X-Override-Profile"
+
+// DefaultProfile applies to requests that name no profile.
+const DefaultProfile = "default"
+
+type Config struct {
+	Listen string `json:"listen"`
+	// Upstream is the node's HTTP, WebSocket or IPC endpoint. WebSocket
+	// clients are relayed to UpstreamWS if set, so subscriptions can work
+	// behind an HTTP Upstream.
+	Upstream   string `json:"upstream"`
+	UpstreamWS string `json:"upstreamWs,omitempty"`
+	// ProfileHeader overrides DefaultProfileHeader.
+	ProfileHeader string `json:"profileHeader,omitempty"`
+	// Profiles maps API keys and profile names to the overrides they get.
+	Profiles map[string]*simulation.Overrides `json:"profiles"`
+}
+
+func LoadConfig(path string) (*Config, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.WithMessage(err, "rpcproxy: read config")
+	}
+	config := &Config{}
+	if err := json.Unmarshal(data, config); err != nil {
+		return nil, errors.WithMessage(err, "rpcproxy: parse config")
+	}
+	if config.Upstream == "" {
+		return nil, errors.New("rpcproxy: config has no upstream")
+	}
+	if config.ProfileHeader == "" {
+		config.ProfileHeader = DefaultProfileHeader
+	}
+	return config, nil
+}
@@ -0,0 +1,95 @@
+package rpcproxy
+
+import (
+	"context"
+	"encoding/json"
+	"geth/simulation"
+	"github.com/ethereum/go-ethereum/rpc"
+	"net"
+	"net/http"
+	"sync"
+)
+
+// Dial connects to rawurl (HTTP, WebSocket or an IPC path) and returns a
+// client whose eth_call, eth_estimateGas and debug_traceCall requests carry
+// overrides. The client talks over an in-memory pipe to a relay that injects
+// the requests on their way to the node; responses and subscription
+// notifications pass through unchanged. The relay and the node connection
+// last until ctx is done or the node connection is lost: rpc.DialIO does not
+// pass on the client's Close. The client does not reconnect.
+func Dial(ctx context.Context, rawurl string, overrides *simulation.Overrides) (*rpc.Client, error) {
+	upstream, err := dialUpstream(ctx, http.DefaultClient, rawurl)
+	if err != nil {
+		return nil, err
+	}
+	conn, relayConn := net.Pipe()
+	client, err := rpc.DialIO(ctx, conn, conn)
+	if err != nil {
+		conn.Close()
+		relayConn.Close()
+		upstream.Close()
+		return nil, err
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		relay(relayConn, upstream, overrides)
+	}()
+	go func() {
+		select {
+		case <-ctx.Done():
+			relayConn.Close()
+		case <-done:
+		}
+	}()
+	return client, nil
+}
+
+// relay copies messages between conn, the relay's end of the client pipe,
+// and upstream, injecting overrides into the client's requests, until either
+// side goes away.
+func relay(conn net.Conn, upstream upstreamConn, overrides *simulation.Overrides) {
+	var mu sync.Mutex
+	write := func(data []byte) error {
+		mu.Lock()
+		defer mu.Unlock()
+		_, err := conn.Write(data)
+		return err
+	}
+
+	go func() {
+		defer conn.Close()
+		for {
+			msg, err := upstream.ReadMessage()
+			if err != nil {
+				return
+			}
+			if err := write(msg); err != nil {
+				return
+			}
+		}
+	}()
+
+	defer upstream.Close()
+	decoder := json.NewDecoder(conn)
+	for {
+		var msg json.RawMessage
+		if err := decoder.Decode(&msg); err != nil {
+			return
+		}
+		if overrides != nil {
+			injected, err := overrides.InjectMessage(msg)
+			if err != nil {
+				if write(errorResponse(msg, errInvalidParams, err)) != nil {
+					return
+				}
+				continue
+			}
+			msg = injected
+		}
+		if err := upstream.WriteMessage(msg); err != nil {
+			return
+		}
+	}
+}
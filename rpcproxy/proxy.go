@@ -0,0 +1,260 @@
+package rpcproxy
+
+import (
+	"bytes"
+	"encoding/json"
+	"geth/simulation"
+	"github.com/gorilla/websocket"
+	"github.com/pkg/errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// maxRequestSize matches geth's HTTP request limit.
+const maxRequestSize = 5 * 1024 * 1024
+
+// JSON-RPC error codes: malformed overrides, and upstream failures.
+const (
+	errInvalidParams = -32602
+	errInternal      = -32603
+)
+
+var errUnknownProfile = errors.New("rpcproxy: unknown api key or profile")
+
+// Proxy serves JSON-RPC requests over HTTP and WebSocket and injects the
+// overrides of the request's profile on the way. It forwards them to the
+// upstream node over HTTP, WebSocket or IPC. The profile is named by the
+// profile header or else by the API key in the URL path (http://proxy/<key>);
+// requests naming neither get DefaultProfile, or pass through untouched when
+// there is none.
+type Proxy struct {
+	config   *Config
+	client   *http.Client
+	upgrader websocket.Upgrader
+}
+
+func New(config *Config) *Proxy {
+	return &Proxy{
+		config: config,
+		client: &http.Client{},
+		upgrader: websocket.Upgrader{
+			// Wallets and test pages connect from arbitrary origins.
+			CheckOrigin: func(*http.Request) bool { return true },
+		},
+	}
+}
+
+func (p *Proxy) profile(r *http.Request) (*simulation.Overrides, error) {
+	name := r.Header.Get(p.config.ProfileHeader)
+	if name == "" {
+		name = strings.Trim(r.URL.Path, "/")
+	}
+	if name == "" {
+		return p.config.Profiles[DefaultProfile], nil
+	}
+	profile, ok := p.config.Profiles[name]
+	if !ok {
+		return nil, errUnknownProfile
+	}
+	return profile, nil
+}
+
+func (p *Proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	profile, err := p.profile(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	if websocket.IsWebSocketUpgrade(r) {
+		p.serveWS(w, r, profile)
+		return
+	}
+	if r.Method != http.MethodPost {
+		http.Error(w, "rpcproxy: use POST", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxRequestSize))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+		return
+	}
+	if profile != nil {
+		injected, err := profile.InjectMessage(body)
+		if err != nil {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write(errorResponse(body, errInvalidParams, err))
+			return
+		}
+		body = injected
+	}
+
+	if !isHTTP(p.config.Upstream) {
+		p.serveStream(w, r, body)
+		return
+	}
+	req, err := http.NewRequestWithContext(r.Context(), http.MethodPost, p.config.Upstream, bytes.NewReader(body))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := p.client.Do(req)
+	if err != nil {
+		http.Error(w, errors.WithMessage(err, "rpcproxy: upstream").Error(), http.StatusBadGateway)
+		return
+	}
+	defer resp.Body.Close()
+	w.Header().Set("Content-Type", resp.Header.Get("Content-Type"))
+	w.WriteHeader(resp.StatusCode)
+	io.Copy(w, resp.Body)
+}
+
+// serveStream answers an HTTP request through a WebSocket or IPC upstream,
+// over a connection of its own.
+func (p *Proxy) serveStream(w http.ResponseWriter, r *http.Request, body []byte) {
+	upstream, err := dialUpstream(r.Context(), p.client, p.config.Upstream)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	defer upstream.Close()
+	if err := upstream.WriteMessage(body); err != nil {
+		http.Error(w, errors.WithMessage(err, "rpcproxy: upstream").Error(), http.StatusBadGateway)
+		return
+	}
+	response, err := upstream.ReadMessage()
+	if err != nil {
+		http.Error(w, errors.WithMessage(err, "rpcproxy: upstream").Error(), http.StatusBadGateway)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(response)
+}
+
+// serveWS relays messages between the client and an upstream connection of
+// its own, so subscriptions keep working. Requests are injected; responses
+// and notifications pass through.
+func (p *Proxy) serveWS(w http.ResponseWriter, r *http.Request, profile *simulation.Overrides) {
+	rawurl := p.config.UpstreamWS
+	if rawurl == "" {
+		rawurl = p.config.Upstream
+	}
+	upstream, err := dialUpstream(r.Context(), p.client, rawurl)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	defer upstream.Close()
+	conn, err := p.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	// Both directions write to conn: responses, and injection errors.
+	var mu sync.Mutex
+	write := func(messageType int, data []byte) error {
+		mu.Lock()
+		defer mu.Unlock()
+		return conn.WriteMessage(messageType, data)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		// Unblock the client read below once upstream is gone.
+		defer conn.Close()
+		for {
+			data, err := upstream.ReadMessage()
+			if err != nil {
+				return
+			}
+			if err := write(websocket.TextMessage, data); err != nil {
+				return
+			}
+		}
+	}()
+
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			break
+		}
+		if profile != nil {
+			injected, err := profile.InjectMessage(data)
+			if err != nil {
+				if write(websocket.TextMessage, errorResponse(data, errInvalidParams, err)) != nil {
+					break
+				}
+				continue
+			}
+			data = injected
+		}
+		if err := upstream.WriteMessage(data); err != nil {
+			break
+		}
+	}
+	upstream.Close()
+	<-done
+}
+
+func isHTTP(rawurl string) bool {
+	return strings.HasPrefix(rawurl, "http://") || strings.HasPrefix(rawurl, "https://")
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type errorMessage struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Error   rpcError        `json:"error"`
+}
+
+// errorResponse answers every call of a single or batch request with err.
+// Notifications get no answer, so nil is returned for them; requests that
+// do not parse are answered with a null id.
+func errorResponse(request []byte, code int, err error) []byte {
+	type call struct {
+		ID json.RawMessage `json:"id"`
+	}
+	var calls []call
+	trimmed := bytes.TrimSpace(request)
+	batch := len(trimmed) > 0 && trimmed[0] == '['
+	if batch {
+		json.Unmarshal(trimmed, &calls)
+	} else {
+		calls = []call{{}}
+		if json.Unmarshal(trimmed, &calls[0]) != nil {
+			calls[0].ID = json.RawMessage("null")
+		}
+	}
+	var ids []json.RawMessage
+	for _, call := range calls {
+		if call.ID != nil {
+			ids = append(ids, call.ID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	msgs := make([]errorMessage, len(ids))
+	for i, id := range ids {
+		msgs[i] = errorMessage{JSONRPC: "2.0", ID: id, Error: rpcError{code, err.Error()}}
+	}
+	var data []byte
+	if batch {
+		data, _ = json.Marshal(msgs)
+	} else {
+		data, _ = json.Marshal(msgs[0])
+	}
+	return data
+}
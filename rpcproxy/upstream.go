@@ -0,0 +1,172 @@
+package rpcproxy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/gorilla/websocket"
+	"github.com/pkg/errors"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+)
+
+var errClosed = errors.New("rpcproxy: upstream closed")
+
+// upstreamConn carries JSON-RPC messages to and from a node. Reads and writes
+// may run concurrently, but not two of the same kind.
+type upstreamConn interface {
+	WriteMessage(data []byte) error
+	ReadMessage() ([]byte, error)
+	Close() error
+}
+
+// dialUpstream connects to an HTTP or WebSocket URL, or else an IPC path, like
+// rpc.DialContext.
+func dialUpstream(ctx context.Context, client *http.Client, rawurl string) (upstreamConn, error) {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return nil, errors.WithMessage(err, "rpcproxy: upstream url")
+	}
+	switch u.Scheme {
+	case "http", "https":
+		return newHTTPUpstream(client, rawurl), nil
+	case "ws", "wss":
+		conn, _, err := websocket.DefaultDialer.DialContext(ctx, rawurl, nil)
+		if err != nil {
+			return nil, errors.WithMessage(err, "rpcproxy: dial upstream")
+		}
+		return wsUpstream{conn}, nil
+	case "":
+		var d net.Dialer
+		conn, err := d.DialContext(ctx, "unix", rawurl)
+		if err != nil {
+			return nil, errors.WithMessage(err, "rpcproxy: dial upstream")
+		}
+		return &ipcUpstream{conn: conn, decoder: json.NewDecoder(conn)}, nil
+	}
+	return nil, errors.Errorf("rpcproxy: unsupported upstream %q", rawurl)
+}
+
+type wsUpstream struct {
+	conn *websocket.Conn
+}
+
+func (u wsUpstream) WriteMessage(data []byte) error {
+	return u.conn.WriteMessage(websocket.TextMessage, data)
+}
+
+func (u wsUpstream) ReadMessage() ([]byte, error) {
+	_, data, err := u.conn.ReadMessage()
+	return data, err
+}
+
+func (u wsUpstream) Close() error {
+	return u.conn.Close()
+}
+
+// ipcUpstream speaks the IPC stream of concatenated JSON values.
+type ipcUpstream struct {
+	conn    net.Conn
+	decoder *json.Decoder
+}
+
+func (u *ipcUpstream) WriteMessage(data []byte) error {
+	_, err := u.conn.Write(data)
+	return err
+}
+
+func (u *ipcUpstream) ReadMessage() ([]byte, error) {
+	var msg json.RawMessage
+	err := u.decoder.Decode(&msg)
+	return msg, err
+}
+
+func (u *ipcUpstream) Close() error {
+	return u.conn.Close()
+}
+
+// httpUpstream posts each message on its own. Responses are read in the
+// order they arrive, which JSON-RPC ids make up for. A failed post is
+// answered with JSON-RPC errors so callers do not wait for a response that
+// never comes.
+type httpUpstream struct {
+	client    *http.Client
+	url       string
+	responses chan []byte
+
+	closeOnce sync.Once
+	closed    chan struct{}
+}
+
+func newHTTPUpstream(client *http.Client, rawurl string) *httpUpstream {
+	return &httpUpstream{
+		client:    client,
+		url:       rawurl,
+		responses: make(chan []byte),
+		closed:    make(chan struct{}),
+	}
+}
+
+func (u *httpUpstream) WriteMessage(data []byte) error {
+	select {
+	case <-u.closed:
+		return errClosed
+	default:
+	}
+	go func() {
+		response, err := u.post(data)
+		if err != nil {
+			response = errorResponse(data, errInternal, err)
+		}
+		if response == nil {
+			// Notifications get no response.
+			return
+		}
+		select {
+		case u.responses <- response:
+		case <-u.closed:
+		}
+	}()
+	return nil
+}
+
+func (u *httpUpstream) post(data []byte) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodPost, u.url, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := u.client.Do(req)
+	if err != nil {
+		return nil, errors.WithMessage(err, "rpcproxy: upstream")
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.WithMessage(err, "rpcproxy: upstream")
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("rpcproxy: upstream %s: %s", resp.Status, bytes.TrimSpace(body))
+	}
+	if len(bytes.TrimSpace(body)) == 0 {
+		return nil, nil
+	}
+	return body, nil
+}
+
+func (u *httpUpstream) ReadMessage() ([]byte, error) {
+	select {
+	case response := <-u.responses:
+		return response, nil
+	case <-u.closed:
+		return nil, errClosed
+	}
+}
+
+func (u *httpUpstream) Close() error {
+	u.closeOnce.Do(func() { close(u.closed) })
+	return nil
+}
@@ -0,0 +1,38 @@
+package simulation
+
+import (
+	"context"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/pkg/errors"
+	"math/big"
+)
+
+// CallFrame is a frame of the native callTracer output.
+type CallFrame struct {
+	Type    string         `json:"type"`
+	From    common.Address `json:"from"`
+	To      common.Address `json:"to,omitempty"`
+	Value   *hexutil.Big   `json:"value,omitempty"`
+	Gas     hexutil.Uint64 `json:"gas"`
+	GasUsed hexutil.Uint64 `json:"gasUsed"`
+	Input   hexutil.Bytes  `json:"input"`
+	Output  hexutil.Bytes  `json:"output,omitempty"`
+	Error   string         `json:"error,omitempty"`
+	Calls   []CallFrame    `json:"calls,omitempty"`
+}
+
+// CallTrace executes args like Trace, returning the call tree instead.
+func (s *Simulator) CallTrace(ctx context.Context, args CallArgs, blockNumber *big.Int, blockOverrides *BlockOverrides) (*CallFrame, error) {
+	config := TraceConfig{
+		Tracer:         "callTracer",
+		Timeout:        "20s",
+		StateOverrides: s.overrides,
+		BlockOverrides: blockOverrides,
+	}
+	var frame CallFrame
+	if err := s.client.CallContext(ctx, &frame, "debug_traceCall", args, toBlockNumArg(blockNumber), config); err != nil {
+		return nil, errors.WithMessage(err, "simulation: debug_traceCall")
+	}
+	return &frame, nil
+}
@@ -0,0 +1,141 @@
+package simulation
+
+import (
+	"context"
+	"fmt"
+	"geth/aggregator"
+	"github.com/pkg/errors"
+	"math/big"
+)
+
+// DefaultFillPrecision is the default search precision of MaxFill, as a
+// fraction of High.
+const DefaultFillPrecision = 1000
+
+// FillRequest is an aggregation router swap whose largest acceptable input
+// amount between Low and High is wanted. From is funded per amount as in
+// Sweep.
+type FillRequest struct {
+	Swap     SwapRequest
+	Registry *Registry
+	ChainID  uint64
+	Low      *big.Int
+	High     *big.Int
+	// Tolerance stops the search once the answer is known within it;
+	// High/DefaultFillPrecision if nil.
+	Tolerance *big.Int
+	// KeepMinRate scales the calldata's minReturnAmount with the amount, so
+	// the router rejects amounts filled below the quoted rate. Otherwise any
+	// output is accepted.
+	KeepMinRate bool
+	// MaxImpact, if positive, also rejects amounts whose price is more than
+	// MaxImpact (0.01 being 1%) below the price at Low.
+	MaxImpact float64
+}
+
+// FillResult is the largest accepted amount found. Amount is nil when Low
+// itself is rejected. Limit is why the smallest rejected amount tried was
+// rejected, empty if High was accepted.
+type FillResult struct {
+	Amount *big.Int
+	Result *SwapResult
+	Impact *big.Float
+	Limit  string
+	Steps  int
+}
+
+// MaxFill bisects between req.Low and req.High for the largest amount the
+// swap accepts, one simulation per step. It assumes that an amount rejected
+// for a revert or for impact stays rejected for larger ones.
+func (s *Simulator) MaxFill(ctx context.Context, req FillRequest, blockNumber *big.Int) (*FillResult, error) {
+	if req.Low == nil || req.High == nil || req.Low.Sign() <= 0 || req.Low.Cmp(req.High) > 0 {
+		return nil, errors.New("simulation: max fill needs 0 < low <= high")
+	}
+	call, err := aggregator.DecodeSwapCall(req.Swap.Data)
+	if err != nil {
+		return nil, errors.WithMessage(err, "simulation: max fill")
+	}
+	tolerance := req.Tolerance
+	if tolerance == nil {
+		tolerance = new(big.Int).Div(req.High, big.NewInt(DefaultFillPrecision))
+	}
+
+	fill := &FillResult{}
+	var basePrice *big.Float
+	// try reports whether amount is accepted, and why not.
+	try := func(amount *big.Int) (*SwapResult, *big.Float, string, error) {
+		fill.Steps++
+		minReturn := big.NewInt(1)
+		if req.KeepMinRate && call.Desc.Amount.Sign() > 0 {
+			minReturn = new(big.Int).Div(new(big.Int).Mul(call.Desc.MinReturnAmount, amount), call.Desc.Amount)
+		}
+		res, err := s.swapAt(ctx, req.Swap, req.Registry, req.ChainID, amount, minReturn, blockNumber)
+		if err != nil {
+			return nil, nil, "", err
+		}
+		if !res.Success {
+			return nil, nil, "reverted: " + res.RevertReason, nil
+		}
+		if res.AmountIn.Sign() == 0 {
+			return nil, nil, "nothing swapped", nil
+		}
+		price := new(big.Float).Quo(new(big.Float).SetInt(res.AmountOut), new(big.Float).SetInt(res.AmountIn))
+		if basePrice == nil {
+			basePrice = price
+		}
+		impact := new(big.Float)
+		if basePrice.Sign() > 0 {
+			impact.Sub(big.NewFloat(1), new(big.Float).Quo(price, basePrice))
+		}
+		if req.MaxImpact > 0 && impact.Cmp(big.NewFloat(req.MaxImpact)) > 0 {
+			return nil, nil, fmt.Sprintf("price impact %s above %g", impact.Text('f', 6), req.MaxImpact), nil
+		}
+		return res, impact, "", nil
+	}
+	accept := func(amount *big.Int, res *SwapResult, impact *big.Float) {
+		fill.Amount, fill.Result, fill.Impact = amount, res, impact
+	}
+
+	res, impact, limit, err := try(req.Low)
+	if err != nil {
+		return nil, err
+	}
+	if limit != "" {
+		fill.Limit = limit
+		return fill, nil
+	}
+	accept(req.Low, res, impact)
+	if req.Low.Cmp(req.High) == 0 {
+		return fill, nil
+	}
+
+	res, impact, limit, err = try(req.High)
+	if err != nil {
+		return nil, err
+	}
+	if limit == "" {
+		accept(req.High, res, impact)
+		return fill, nil
+	}
+	fill.Limit = limit
+
+	low, high := new(big.Int).Set(req.Low), new(big.Int).Set(req.High)
+	for new(big.Int).Sub(high, low).Cmp(tolerance) > 0 {
+		mid := new(big.Int).Rsh(new(big.Int).Add(low, high), 1)
+		if mid.Cmp(low) == 0 {
+			break
+		}
+		res, impact, limit, err := try(mid)
+		if err != nil {
+			return nil, err
+		}
+		if limit == "" {
+			low = mid
+			accept(mid, res, impact)
+		} else {
+			high = mid
+			fill.Limit = limit
+		}
+	}
+	return fill, nil
+}
@@ -0,0 +1,170 @@
+package simulation
+
+import (
+	"context"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/pkg/errors"
+	"math/big"
+)
+
+const (
+	// DefaultGasCap is the highest limit EstimateGas tries, the mainnet
+	// block gas limit.
+	DefaultGasCap = 30000000
+	// DefaultGasMargin is the share of the required limit added to the
+	// recommended one.
+	DefaultGasMargin = 0.2
+)
+
+type GasConfig struct {
+	// Cap is the highest limit tried, DefaultGasCap if zero.
+	Cap uint64
+	// Margin is added to the required limit for the recommendation, 0.1
+	// being 10%. Zero means DefaultGasMargin, a negative value none.
+	Margin float64
+	// Succeeded, if set, also judges calls that did not revert by their
+	// return data, for wrappers such as SimSwap that catch the failure of
+	// the call they make.
+	Succeeded func(returnData []byte) bool
+}
+
+// GasEstimate is the gas a call needs under the simulator's overrides.
+type GasEstimate struct {
+	// GasUsed and Refund are what a receipt would show, GasUsed being net
+	// of Refund. Both are zero if the node cannot trace.
+	GasUsed uint64
+	Refund  uint64
+	// Required is the lowest limit the call succeeds with. It exceeds
+	// GasUsed by the refund, only paid back once the call is done, and by
+	// the gas the 63/64 rule keeps back from nested calls.
+	Required uint64
+	// Recommended is Required plus the margin, at most the cap.
+	Recommended uint64
+	// Steps is the number of simulations the search took.
+	Steps int
+}
+
+// Withheld is the part of Required that GasUsed and Refund do not explain:
+// mostly the 1/64 of the remaining gas each call frame keeps from the ones
+// it calls.
+func (e *GasEstimate) Withheld() uint64 {
+	if e.GasUsed == 0 || e.Required < e.GasUsed+e.Refund {
+		return 0
+	}
+	return e.Required - e.GasUsed - e.Refund
+}
+
+func (e *GasEstimate) String() string {
+	if e.GasUsed == 0 {
+		return fmt.Sprintf("required %d, recommended %d", e.Required, e.Recommended)
+	}
+	return fmt.Sprintf("gasUsed %d, refund %d, withheld %d, required %d, recommended %d",
+		e.GasUsed, e.Refund, e.Withheld(), e.Required, e.Recommended)
+}
+
+// EstimateGas binary-searches the lowest gas limit args succeed with at
+// blockNumber, with the simulator's state overrides, which eth_estimateGas
+// does not take on every node. A trace, where the node supports it, gives
+// the gas used and narrows the search.
+func (s *Simulator) EstimateGas(ctx context.Context, args CallArgs, blockNumber *big.Int, config GasConfig) (*GasEstimate, error) {
+	limit := config.Cap
+	if limit == 0 {
+		limit = DefaultGasCap
+	}
+	margin := config.Margin
+	if margin == 0 {
+		margin = DefaultGasMargin
+	}
+
+	est := &GasEstimate{}
+	withGas := func(gas uint64) CallArgs {
+		g := hexutil.Uint64(gas)
+		a := args
+		a.Gas = &g
+		return a
+	}
+	// fails reports whether the call fails with gas. Simulate reports out of
+	// gas as a failed call like a revert; its errors are the node's.
+	fails := func(gas uint64) (bool, string, error) {
+		est.Steps++
+		res, err := s.Simulate(ctx, withGas(gas), blockNumber)
+		if err != nil {
+			return false, "", err
+		}
+		if !res.Failed && config.Succeeded != nil && !config.Succeeded(res.ReturnData) {
+			return true, "rejected by Succeeded", nil
+		}
+		return res.Failed, res.RevertReason, nil
+	}
+
+	failed, reason, err := fails(limit)
+	if err != nil {
+		return nil, err
+	}
+	if failed {
+		return nil, errors.Errorf("simulation: call fails with gas cap %d: %s", limit, reason)
+	}
+
+	var data []byte
+	if args.Data != nil {
+		data = *args.Data
+	}
+	var accessList types.AccessList
+	if args.AccessList != nil {
+		accessList = *args.AccessList
+	}
+	intrinsic, err := core.IntrinsicGas(data, accessList, args.To == nil, true, true)
+	if err != nil {
+		return nil, errors.WithMessage(err, "simulation: intrinsic gas")
+	}
+	lo, hi := intrinsic-1, limit
+
+	if trace, err := s.Trace(ctx, withGas(limit), blockNumber, nil); err == nil && !trace.Failed {
+		est.GasUsed, est.Refund = trace.GasUsed, trace.Refund
+		// The refund is only paid back at the end, so the limit must cover
+		// the gas spent before it.
+		spent := trace.GasUsed + trace.Refund
+		if spent-1 > lo {
+			lo = spent - 1
+		}
+		// Most calls pass with what the 63/64 rule keeps back on top: try
+		// that first to narrow the search.
+		if guess := spent + spent/63 + params.CallStipend; guess < hi {
+			failed, _, err := fails(guess)
+			if err != nil {
+				return nil, err
+			}
+			if failed {
+				lo = guess
+			} else {
+				hi = guess
+			}
+		}
+	}
+
+	for hi-lo > 1 {
+		mid := lo + (hi-lo)/2
+		failed, _, err := fails(mid)
+		if err != nil {
+			return nil, err
+		}
+		if failed {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	est.Required = hi
+	est.Recommended = hi
+	if margin > 0 {
+		est.Recommended += uint64(float64(hi) * margin)
+	}
+	if est.Recommended > limit {
+		est.Recommended = limit
+	}
+	return est, nil
+}
@@ -0,0 +1,127 @@
+package simulation
+
+import (
+	"context"
+	"fmt"
+	"geth/contract/simswap"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/pkg/errors"
+	"math/big"
+	"strings"
+)
+
+var (
+	// HoneypotBuyer is the fresh address the honeypot check buys from.
+	HoneypotBuyer = common.HexToAddress("0x1111111111111111111111111111111111111103")
+
+	// ExtremeTaxBps is the buy or sell tax from which a token is flagged.
+	ExtremeTaxBps uint64 = 5000
+)
+
+// HoneypotReport is the outcome of buying a token and selling it right back.
+type HoneypotReport struct {
+	Token common.Address
+	// Bought is what arrived for the ETH spent, BuyExpected the router quote.
+	Bought      *big.Int
+	BuyExpected *big.Int
+	BuyTaxBps   uint64
+	// TransferOK reports whether the buyer could send tokens to another
+	// fresh address.
+	TransferOK bool
+	SellOK     bool
+	// Sold is the ETH the sell returned, SellExpected the router quote.
+	Sold         *big.Int
+	SellExpected *big.Int
+	SellTaxBps   uint64
+	SellReason   string
+}
+
+// Flags lists why the token should not be routed through, empty when it
+// looks safe.
+func (r *HoneypotReport) Flags() []string {
+	var flags []string
+	if !r.SellOK {
+		flags = append(flags, "sell reverts: "+r.SellReason)
+	}
+	if !r.TransferOK {
+		flags = append(flags, "transfer blocked for non-whitelisted sender")
+	}
+	if r.BuyTaxBps >= ExtremeTaxBps {
+		flags = append(flags, fmt.Sprintf("buy tax %d bps", r.BuyTaxBps))
+	}
+	if r.SellOK && r.SellTaxBps >= ExtremeTaxBps {
+		flags = append(flags, fmt.Sprintf("sell tax %d bps", r.SellTaxBps))
+	}
+	return flags
+}
+
+func (r *HoneypotReport) Honeypot() bool {
+	return len(r.Flags()) > 0
+}
+
+func (r *HoneypotReport) String() string {
+	if flags := r.Flags(); len(flags) > 0 {
+		return fmt.Sprintf("%s honeypot: %s", r.Token.Hex(), strings.Join(flags, ", "))
+	}
+	return fmt.Sprintf("%s buy tax %d bps, sell tax %d bps", r.Token.Hex(), r.BuyTaxBps, r.SellTaxBps)
+}
+
+// CheckHoneypot buys token for ethIn through the Uniswap V2 compatible
+// router, moves 1% of it to a fresh address and sells the rest back, all
+// within one eth_call from a fresh, overridden address. An error is returned
+// when the buy itself fails, e.g. when the token has no WETH pair.
+func (s *Simulator) CheckHoneypot(ctx context.Context, router, token common.Address, ethIn *big.Int, blockNumber *big.Int) (*HoneypotReport, error) {
+	data, err := simswapAbi.Pack("honeypot", router, token)
+	if err != nil {
+		return nil, errors.WithMessage(err, "simulation: pack honeypot")
+	}
+	input := hexutil.Bytes(data)
+	args := CallArgs{
+		From:  &HoneypotBuyer,
+		To:    &SimSwapAddress,
+		Value: (*hexutil.Big)(ethIn),
+		Data:  &input,
+	}
+
+	sim := s.WithOverrides(OverrideAccounts{
+		SimSwapAddress: {Code: simswap.RuntimeCode},
+		HoneypotBuyer:  {Balance: hexutil.EncodeBig(new(big.Int).Mul(ethIn, big.NewInt(2)))},
+	})
+	res, err := sim.Simulate(ctx, args, blockNumber)
+	if err != nil {
+		return nil, err
+	}
+	if res.Failed {
+		return nil, errors.Errorf("simulation: honeypot buy reverted: %s", res.RevertReason)
+	}
+	out, err := simswapAbi.Unpack("honeypot", res.ReturnData)
+	if err != nil {
+		return nil, errors.WithMessage(err, "simulation: unpack honeypot")
+	}
+	report := &HoneypotReport{
+		Token:        token,
+		Bought:       out[0].(*big.Int),
+		BuyExpected:  out[1].(*big.Int),
+		TransferOK:   out[2].(bool),
+		SellOK:       out[3].(bool),
+		Sold:         out[4].(*big.Int),
+		SellExpected: out[5].(*big.Int),
+	}
+	report.BuyTaxBps = taxBps(report.Bought, report.BuyExpected)
+	if report.SellOK {
+		report.SellTaxBps = taxBps(report.Sold, report.SellExpected)
+	} else {
+		report.SellReason = DecodeRevert(out[6].([]byte))
+	}
+	return report, nil
+}
+
+// taxBps is the share of expected that did not arrive, in basis points.
+func taxBps(got, expected *big.Int) uint64 {
+	if expected.Sign() == 0 || got.Cmp(expected) >= 0 {
+		return 0
+	}
+	missing := new(big.Int).Sub(expected, got)
+	return missing.Mul(missing, big.NewInt(10000)).Div(missing, expected).Uint64()
+}
@@ -0,0 +1,183 @@
+package simulation
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/pkg/errors"
+)
+
+// Overrides are the state and block overrides injected into the calls of a
+// JSON-RPC message.
+type Overrides struct {
+	StateOverrides OverrideAccounts `json:"stateOverrides,omitempty"`
+	BlockOverrides *BlockOverrides  `json:"blockOverrides,omitempty"`
+	// EstimateGas also injects the state overrides into eth_estimateGas,
+	// which older nodes (geth 1.10 included) reject with a third parameter.
+	EstimateGas bool `json:"estimateGas,omitempty"`
+	// CallBlockOverrides also injects the block overrides into eth_call as a
+	// fourth parameter, which geth 1.10 rejects too. Otherwise they only go
+	// to debug_traceCall.
+	CallBlockOverrides bool `json:"callBlockOverrides,omitempty"`
+}
+
+// rpcMessage is a JSON-RPC request; only the params are touched.
+type rpcMessage struct {
+	JSONRPC string            `json:"jsonrpc"`
+	ID      json.RawMessage   `json:"id,omitempty"`
+	Method  string            `json:"method"`
+	Params  []json.RawMessage `json:"params"`
+}
+
+// InjectMessage applies o to a single or batch JSON-RPC request body. Bodies
+// that do not parse as requests are returned as they are, for the node to
+// reject.
+func (o *Overrides) InjectMessage(body []byte) ([]byte, error) {
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) > 0 && trimmed[0] == '[' {
+		var batch []json.RawMessage
+		if err := json.Unmarshal(trimmed, &batch); err != nil {
+			return body, nil
+		}
+		for i, msg := range batch {
+			injected, err := o.InjectMessage(msg)
+			if err != nil {
+				return nil, err
+			}
+			batch[i] = injected
+		}
+		return json.Marshal(batch)
+	}
+
+	var msg rpcMessage
+	if err := json.Unmarshal(trimmed, &msg); err != nil || msg.Method == "" {
+		return body, nil
+	}
+	params, err := o.InjectParams(msg.Method, msg.Params)
+	if err != nil {
+		return nil, err
+	}
+	if params == nil {
+		return body, nil
+	}
+	msg.Params = params
+	return json.Marshal(msg)
+}
+
+// InjectParams adds o to the params of eth_call (state overrides, and block
+// overrides if enabled), eth_estimateGas (state overrides, if enabled) and
+// debug_traceCall (both, inside the trace config). Overrides the caller
+// already passes are applied on top of o. A nil result means method takes no
+// overrides.
+func (o *Overrides) InjectParams(method string, params []json.RawMessage) ([]json.RawMessage, error) {
+	switch method {
+	case "eth_call", "eth_estimateGas":
+		if len(params) == 0 || method == "eth_estimateGas" && !o.EstimateGas {
+			return nil, nil
+		}
+		params = withBlock(params)
+		state, err := o.mergeState(param(params, 2))
+		if err != nil {
+			return nil, errors.WithMessage(err, method)
+		}
+		params = setParam(params, 2, state)
+		if method == "eth_call" && o.BlockOverrides != nil && o.CallBlockOverrides {
+			block, err := o.mergeBlock(param(params, 3))
+			if err != nil {
+				return nil, errors.WithMessage(err, method)
+			}
+			params = setParam(params, 3, block)
+		}
+		return params, nil
+
+	case "debug_traceCall":
+		if len(params) == 0 {
+			return nil, nil
+		}
+		params = withBlock(params)
+		config := make(map[string]json.RawMessage)
+		if raw := param(params, 2); raw != nil {
+			if err := json.Unmarshal(raw, &config); err != nil {
+				return nil, errors.WithMessage(err, "debug_traceCall: trace config")
+			}
+		}
+		state, err := o.mergeState(config["stateOverrides"])
+		if err != nil {
+			return nil, errors.WithMessage(err, method)
+		}
+		config["stateOverrides"] = state
+		if o.BlockOverrides != nil {
+			block, err := o.mergeBlock(config["blockOverrides"])
+			if err != nil {
+				return nil, errors.WithMessage(err, method)
+			}
+			config["blockOverrides"] = block
+		}
+		data, err := json.Marshal(config)
+		if err != nil {
+			return nil, err
+		}
+		return setParam(params, 2, data), nil
+	}
+	return nil, nil
+}
+
+// mergeState applies the caller's state overrides on top of o's.
+func (o *Overrides) mergeState(raw json.RawMessage) (json.RawMessage, error) {
+	var caller OverrideAccounts
+	if raw != nil {
+		if err := json.Unmarshal(raw, &caller); err != nil {
+			return nil, errors.WithMessage(err, "state overrides")
+		}
+	}
+	return json.Marshal(o.StateOverrides.Merge(caller))
+}
+
+// mergeBlock applies the fields the caller sets on top of o's block
+// overrides.
+func (o *Overrides) mergeBlock(raw json.RawMessage) (json.RawMessage, error) {
+	data, err := json.Marshal(o.BlockOverrides)
+	if err != nil {
+		return nil, err
+	}
+	fields := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	if raw != nil && !isNull(raw) {
+		caller := make(map[string]json.RawMessage)
+		if err := json.Unmarshal(raw, &caller); err != nil {
+			return nil, errors.WithMessage(err, "block overrides")
+		}
+		for name, value := range caller {
+			fields[name] = value
+		}
+	}
+	return json.Marshal(fields)
+}
+
+// withBlock fills in the block parameter, which must precede the overrides.
+func withBlock(params []json.RawMessage) []json.RawMessage {
+	if len(params) < 2 || isNull(params[1]) {
+		return setParam(params, 1, json.RawMessage(`"latest"`))
+	}
+	return params
+}
+
+func param(params []json.RawMessage, i int) json.RawMessage {
+	if i >= len(params) || isNull(params[i]) {
+		return nil
+	}
+	return params[i]
+}
+
+func setParam(params []json.RawMessage, i int, value json.RawMessage) []json.RawMessage {
+	for len(params) <= i {
+		params = append(params, json.RawMessage("null"))
+	}
+	params[i] = value
+	return params
+}
+
+func isNull(raw json.RawMessage) bool {
+	return len(raw) == 0 || bytes.Equal(bytes.TrimSpace(raw), []byte("null"))
+}
@@ -0,0 +1,160 @@
+package simulation
+
+import (
+	"geth/aggregator"
+	"geth/contract/aggregation_router"
+	"geth/contract/erc20"
+	"geth/contract/uniswap_v2_pair"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"math/big"
+	"sort"
+	"strings"
+)
+
+// wethAbi holds the WETH events, which move balances without a Transfer.
+const wethAbi = `[
+	{"name":"Deposit","type":"event","anonymous":false,"inputs":[{"indexed":true,"name":"dst","type":"address"},{"indexed":false,"name":"wad","type":"uint256"}]},
+	{"name":"Withdrawal","type":"event","anonymous":false,"inputs":[{"indexed":true,"name":"src","type":"address"},{"indexed":false,"name":"wad","type":"uint256"}]}
+]`
+
+// events maps topic 0 to the known events: ERC20, WETH, Uniswap V2 pairs and
+// the aggregation router. The first ABI declaring an event wins.
+var events = make(map[common.Hash]abi.Event)
+
+func init() {
+	for _, def := range []string{erc20.ContractMetaData.ABI, wethAbi, uniswap_v2_pair.ContractMetaData.ABI, aggregation_router.ContractMetaData.ABI} {
+		parsed, err := abi.JSON(strings.NewReader(def))
+		if err != nil {
+			panic(err)
+		}
+		for _, event := range parsed.Events {
+			if _, ok := events[event.ID]; !ok {
+				events[event.ID] = event
+			}
+		}
+	}
+}
+
+// DecodedLog is a log with its event decoded. Event is empty when the log is
+// not a known event; Args then stays nil.
+type DecodedLog struct {
+	Address common.Address         `json:"address"`
+	Topics  []common.Hash          `json:"topics"`
+	Data    hexutil.Bytes          `json:"data"`
+	Event   string                 `json:"event,omitempty"`
+	Args    map[string]interface{} `json:"args,omitempty"`
+}
+
+// DecodeLog decodes l against the known events. Integers are returned as
+// decimal strings and byte arrays as hex, so Args marshals losslessly.
+func DecodeLog(l *types.Log) *DecodedLog {
+	decoded := &DecodedLog{Address: l.Address, Topics: l.Topics, Data: l.Data}
+	if len(l.Topics) == 0 {
+		return decoded
+	}
+	event, ok := events[l.Topics[0]]
+	if !ok {
+		return decoded
+	}
+	var indexed abi.Arguments
+	for _, arg := range event.Inputs {
+		if arg.Indexed {
+			indexed = append(indexed, arg)
+		}
+	}
+	if len(indexed) != len(l.Topics)-1 {
+		// Same signature, different indexing, e.g. an ERC721 Transfer.
+		return decoded
+	}
+	args := make(map[string]interface{})
+	if err := event.Inputs.UnpackIntoMap(args, l.Data); err != nil {
+		return decoded
+	}
+	if err := abi.ParseTopicsIntoMap(args, indexed, l.Topics[1:]); err != nil {
+		return decoded
+	}
+	for name, value := range args {
+		args[name] = jsonValue(value)
+	}
+	decoded.Event, decoded.Args = event.Name, args
+	return decoded
+}
+
+func jsonValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case *big.Int:
+		return v.String()
+	case []byte:
+		return hexutil.Bytes(v)
+	case [32]byte:
+		return common.Hash(v)
+	}
+	return value
+}
+
+// AssetChange is the net balance change of Owner in Token, the native token
+// being aggregator.NativeToken.
+type AssetChange struct {
+	Token common.Address `json:"token"`
+	Owner common.Address `json:"owner"`
+	Delta *big.Int       `json:"delta"`
+}
+
+// AssetChanges nets the ERC20 Transfer, WETH Deposit/Withdrawal and ETH
+// transfers of res per token and owner. Zero changes are dropped; the rest
+// are sorted by token and owner.
+func AssetChanges(res *TraceResult) []AssetChange {
+	type key struct{ token, owner common.Address }
+	deltas := make(map[key]*big.Int)
+	add := func(token, owner common.Address, amount *big.Int) {
+		k := key{token, owner}
+		if deltas[k] == nil {
+			deltas[k] = new(big.Int)
+		}
+		deltas[k].Add(deltas[k], amount)
+	}
+	for _, l := range res.Logs {
+		decoded := DecodeLog(l)
+		switch decoded.Event {
+		case "Transfer":
+			amount, ok := new(big.Int).SetString(decoded.Args["value"].(string), 10)
+			if !ok {
+				continue
+			}
+			add(l.Address, decoded.Args["from"].(common.Address), new(big.Int).Neg(amount))
+			add(l.Address, decoded.Args["to"].(common.Address), amount)
+		case "Deposit", "Withdrawal":
+			amount, ok := new(big.Int).SetString(decoded.Args["wad"].(string), 10)
+			if !ok {
+				continue
+			}
+			if decoded.Event == "Deposit" {
+				add(l.Address, decoded.Args["dst"].(common.Address), amount)
+			} else {
+				add(l.Address, decoded.Args["src"].(common.Address), amount.Neg(amount))
+			}
+		}
+	}
+	for _, transfer := range res.Transfers {
+		amount := transfer.Value.ToInt()
+		add(aggregator.NativeToken, transfer.From, new(big.Int).Neg(amount))
+		add(aggregator.NativeToken, transfer.To, amount)
+	}
+
+	changes := make([]AssetChange, 0, len(deltas))
+	for k, delta := range deltas {
+		if delta.Sign() != 0 {
+			changes = append(changes, AssetChange{Token: k.token, Owner: k.owner, Delta: delta})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].Token != changes[j].Token {
+			return changes[i].Token.Hex() < changes[j].Token.Hex()
+		}
+		return changes[i].Owner.Hex() < changes[j].Owner.Hex()
+	})
+	return changes
+}
@@ -0,0 +1,231 @@
+package simulation
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"geth/aggregator"
+	"geth/contract/erc20"
+	"geth/contract/simswap"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/pkg/errors"
+	"math/big"
+	"strings"
+	"sync"
+)
+
+// metadataAbi holds the optional ERC20 metadata methods, which IERC20.abi
+// does not declare.
+const metadataAbi = `[
+	{"name":"name","type":"function","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"string"}]},
+	{"name":"symbol","type":"function","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"string"}]},
+	{"name":"decimals","type":"function","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"uint8"}]}
+]`
+
+var (
+	erc20Abi    abi.ABI
+	metaAbi     abi.ABI
+	metaMethods = []string{"name", "symbol", "decimals", "totalSupply"}
+)
+
+// metadataCallGas caps each metadata call; they are plain storage reads.
+const metadataCallGas = 100000
+
+func init() {
+	var err error
+	erc20Abi, err = abi.JSON(strings.NewReader(erc20.ContractMetaData.ABI))
+	if err != nil {
+		panic(err)
+	}
+	metaAbi, err = abi.JSON(strings.NewReader(metadataAbi))
+	if err != nil {
+		panic(err)
+	}
+}
+
+// TokenMetadata describes an ERC20 token. Fields a token does not implement
+// are left empty; TotalSupply is as of the first lookup.
+type TokenMetadata struct {
+	Address     common.Address
+	Name        string
+	Symbol      string
+	Decimals    uint8
+	TotalSupply *big.Int
+}
+
+// FormatAmount renders amount in whole tokens followed by the symbol, e.g.
+// "1000.5 DAI".
+func (m *TokenMetadata) FormatAmount(amount *big.Int) string {
+	symbol := m.Symbol
+	if symbol == "" {
+		symbol = m.Address.Hex()
+	}
+	return FormatUnits(amount, m.Decimals) + " " + symbol
+}
+
+// FormatUnits renders amount divided by 10^decimals without rounding.
+func FormatUnits(amount *big.Int, decimals uint8) string {
+	if decimals == 0 {
+		return amount.String()
+	}
+	unit := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil)
+	whole, frac := new(big.Int).QuoRem(new(big.Int).Abs(amount), unit, new(big.Int))
+	s := whole.String()
+	if frac.Sign() != 0 {
+		s += "." + strings.TrimRight(fmt.Sprintf("%0*s", decimals, frac.String()), "0")
+	}
+	if amount.Sign() < 0 {
+		s = "-" + s
+	}
+	return s
+}
+
+// MetadataCache looks up token metadata through SimSwap's multicall, one
+// eth_call for any number of tokens, and keeps the results.
+type MetadataCache struct {
+	sim *Simulator
+
+	mu     sync.Mutex
+	tokens map[common.Address]*TokenMetadata
+}
+
+func NewMetadataCache(sim *Simulator) *MetadataCache {
+	return &MetadataCache{
+		sim:    sim,
+		tokens: make(map[common.Address]*TokenMetadata),
+	}
+}
+
+// Get returns the metadata of tokens, fetching the ones not cached yet. The
+// native token sentinel is answered as ETH without a call.
+func (c *MetadataCache) Get(ctx context.Context, tokens ...common.Address) (map[common.Address]*TokenMetadata, error) {
+	res := make(map[common.Address]*TokenMetadata, len(tokens))
+	var missing []common.Address
+	c.mu.Lock()
+	for _, token := range tokens {
+		if m, ok := c.tokens[token]; ok {
+			res[token] = m
+		} else if token == aggregator.NativeToken {
+			res[token] = &TokenMetadata{Address: token, Name: "Ether", Symbol: "ETH", Decimals: 18}
+		} else if _, seen := res[token]; !seen {
+			missing = append(missing, token)
+			res[token] = nil
+		}
+	}
+	c.mu.Unlock()
+	if len(missing) == 0 {
+		return res, nil
+	}
+
+	fetched, err := c.fetch(ctx, missing)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	for _, m := range fetched {
+		c.tokens[m.Address] = m
+		res[m.Address] = m
+	}
+	c.mu.Unlock()
+	return res, nil
+}
+
+// Token returns the metadata of a single token.
+func (c *MetadataCache) Token(ctx context.Context, token common.Address) (*TokenMetadata, error) {
+	res, err := c.Get(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	return res[token], nil
+}
+
+func (c *MetadataCache) fetch(ctx context.Context, tokens []common.Address) ([]*TokenMetadata, error) {
+	targets := make([]common.Address, 0, len(tokens)*len(metaMethods))
+	calls := make([][]byte, 0, len(tokens)*len(metaMethods))
+	for _, token := range tokens {
+		for _, method := range metaMethods {
+			var data []byte
+			if method == "totalSupply" {
+				data, _ = erc20Abi.Pack(method)
+			} else {
+				data, _ = metaAbi.Pack(method)
+			}
+			targets = append(targets, token)
+			calls = append(calls, data)
+		}
+	}
+
+	success, results, err := c.sim.multicall(ctx, targets, calls, metadataCallGas, nil)
+	if err != nil {
+		return nil, err
+	}
+	metadata := make([]*TokenMetadata, len(tokens))
+	for i, token := range tokens {
+		m := &TokenMetadata{Address: token}
+		for j, method := range metaMethods {
+			k := i*len(metaMethods) + j
+			if !success[k] {
+				continue
+			}
+			switch method {
+			case "name":
+				m.Name = decodeString(results[k])
+			case "symbol":
+				m.Symbol = decodeString(results[k])
+			case "decimals":
+				if len(results[k]) >= 32 {
+					m.Decimals = uint8(new(big.Int).SetBytes(results[k][:32]).Uint64())
+				}
+			case "totalSupply":
+				if len(results[k]) >= 32 {
+					m.TotalSupply = new(big.Int).SetBytes(results[k][:32])
+				}
+			}
+		}
+		metadata[i] = m
+	}
+	return metadata, nil
+}
+
+// multicall runs read-only calls through SimSwap.multicall in one eth_call,
+// each with at most gasPerCall gas.
+func (s *Simulator) multicall(ctx context.Context, targets []common.Address, calls [][]byte, gasPerCall uint64, blockNumber *big.Int) ([]bool, [][]byte, error) {
+	data, err := simswapAbi.Pack("multicall", targets, calls, new(big.Int).SetUint64(gasPerCall))
+	if err != nil {
+		return nil, nil, errors.WithMessage(err, "simulation: pack multicall")
+	}
+	input := hexutil.Bytes(data)
+	args := CallArgs{
+		From: &SimSwapAddress,
+		To:   &SimSwapAddress,
+		Data: &input,
+	}
+	sim := s.WithOverrides(OverrideAccounts{SimSwapAddress: {Code: simswap.RuntimeCode}})
+	res, err := sim.Simulate(ctx, args, blockNumber)
+	if err != nil {
+		return nil, nil, err
+	}
+	if res.Failed {
+		return nil, nil, errors.Errorf("simulation: multicall reverted: %s", res.RevertReason)
+	}
+	out, err := simswapAbi.Unpack("multicall", res.ReturnData)
+	if err != nil {
+		return nil, nil, errors.WithMessage(err, "simulation: unpack multicall")
+	}
+	return out[0].([]bool), out[1].([][]byte), nil
+}
+
+// decodeString decodes a string return value, or a bytes32 one as returned
+// by tokens like MKR.
+func decodeString(data []byte) string {
+	if len(data) == 32 {
+		return string(bytes.TrimRight(data, "\x00"))
+	}
+	out, err := metaAbi.Methods["name"].Outputs.Unpack(data)
+	if err != nil {
+		return ""
+	}
+	return out[0].(string)
+}
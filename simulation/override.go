@@ -0,0 +1,67 @@
+package simulation
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Account is the state override applied to a single address during eth_call.
+type Account struct {
+	Nonce     string            `json:"nonce,omitempty"`
+	Balance   string            `json:"balance,omitempty"`
+	Code      string            `json:"code,omitempty"`
+	State     map[string]string `json:"state,omitempty"`
+	StateDiff map[string]string `json:"stateDiff,omitempty"`
+}
+
+type OverrideAccounts map[common.Address]Account
+
+// Merge returns a copy of o with other applied on top. Fields set in other
+// replace those in o, and storage entries are merged slot by slot.
+func (o OverrideAccounts) Merge(other OverrideAccounts) OverrideAccounts {
+	merged := make(OverrideAccounts, len(o)+len(other))
+	for addr, acc := range o {
+		merged[addr] = acc
+	}
+	for addr, acc := range other {
+		base, ok := merged[addr]
+		if !ok {
+			merged[addr] = acc
+			continue
+		}
+		if acc.Nonce != "" {
+			base.Nonce = acc.Nonce
+		}
+		if acc.Balance != "" {
+			base.Balance = acc.Balance
+		}
+		if acc.Code != "" {
+			base.Code = acc.Code
+		}
+		if acc.State != nil {
+			// A full state replacement discards any earlier diff.
+			base.State = copySlots(acc.State, nil)
+			base.StateDiff = nil
+		}
+		if acc.StateDiff != nil {
+			// geth rejects accounts carrying both state and stateDiff.
+			if base.State != nil {
+				base.State = copySlots(base.State, acc.StateDiff)
+			} else {
+				base.StateDiff = copySlots(base.StateDiff, acc.StateDiff)
+			}
+		}
+		merged[addr] = base
+	}
+	return merged
+}
+
+func copySlots(base, extra map[string]string) map[string]string {
+	slots := make(map[string]string, len(base)+len(extra))
+	for k, v := range base {
+		slots[k] = v
+	}
+	for k, v := range extra {
+		slots[k] = v
+	}
+	return slots
+}
@@ -0,0 +1,143 @@
+package simulation
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"sync"
+	"time"
+)
+
+// DefaultWorkers is the number of jobs a Pool runs at once unless configured.
+const DefaultWorkers = 16
+
+var ErrPoolClosed = errors.New("simulation: pool closed")
+
+// Job is a unit of work for a Pool. Run gets the pool's Simulator and a
+// context carrying the job deadline.
+type Job struct {
+	ID  string
+	Run func(ctx context.Context, sim *Simulator) (interface{}, error)
+}
+
+// JobResult is the outcome of a Job. Err is the job's error, its deadline
+// passing or a panic in Run.
+type JobResult struct {
+	ID       string
+	Value    interface{}
+	Err      error
+	Duration time.Duration
+}
+
+type PoolConfig struct {
+	// Workers is the number of jobs run at once, DefaultWorkers if zero.
+	// Per-endpoint RPC limits belong to the Simulator's client, e.g. an
+	// rpcpool.Pool.
+	Workers int
+	// Queue is the number of submitted jobs waiting for a worker.
+	Queue int
+	// Timeout bounds each job; zero means only ctx does.
+	Timeout time.Duration
+}
+
+// Pool runs jobs concurrently against one Simulator and streams their
+// results. A failing job only fails its own result.
+type Pool struct {
+	sim     *Simulator
+	config  PoolConfig
+	ctx     context.Context
+	jobs    chan Job
+	results chan JobResult
+	wg      sync.WaitGroup
+
+	mu     sync.RWMutex
+	closed bool
+}
+
+// NewPool starts the workers; cancelling ctx cancels the running jobs.
+func NewPool(ctx context.Context, sim *Simulator, config PoolConfig) *Pool {
+	if config.Workers <= 0 {
+		config.Workers = DefaultWorkers
+	}
+	p := &Pool{
+		sim:     sim,
+		config:  config,
+		ctx:     ctx,
+		jobs:    make(chan Job, config.Queue),
+		results: make(chan JobResult, config.Workers),
+	}
+	p.wg.Add(config.Workers)
+	for i := 0; i < config.Workers; i++ {
+		go p.work()
+	}
+	return p
+}
+
+// Submit queues job, waiting for room until ctx is done.
+func (p *Pool) Submit(ctx context.Context, job Job) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.closed {
+		return ErrPoolClosed
+	}
+	select {
+	case p.jobs <- job:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Results delivers one result per submitted job, in completion order. It is
+// closed after Close once every job is done. Workers wait for results to be
+// received, so it must be drained.
+func (p *Pool) Results() <-chan JobResult {
+	return p.results
+}
+
+// Close stops accepting jobs. The queued ones still run.
+func (p *Pool) Close() {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return
+	}
+	p.closed = true
+	close(p.jobs)
+	p.mu.Unlock()
+
+	go func() {
+		p.wg.Wait()
+		close(p.results)
+	}()
+}
+
+func (p *Pool) work() {
+	defer p.wg.Done()
+	for job := range p.jobs {
+		p.results <- p.run(job)
+	}
+}
+
+func (p *Pool) run(job Job) (result JobResult) {
+	start := time.Now()
+	result.ID = job.ID
+	defer func() {
+		if r := recover(); r != nil {
+			result.Err = errors.Errorf("simulation: job %s panicked: %v", job.ID, r)
+		}
+		result.Duration = time.Since(start)
+	}()
+
+	ctx := p.ctx
+	if p.config.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.config.Timeout)
+		defer cancel()
+	}
+	if err := ctx.Err(); err != nil {
+		result.Err = err
+		return result
+	}
+	result.Value, result.Err = job.Run(ctx, p.sim)
+	return result
+}
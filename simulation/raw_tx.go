@@ -0,0 +1,95 @@
+package simulation
+
+import (
+	"context"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+	"math/big"
+)
+
+var (
+	ErrNonceTooLow  = errors.New("nonce too low")
+	ErrNonceTooHigh = errors.New("nonce too high")
+)
+
+// DecodeRawTransaction decodes a signed legacy, EIP-2930 or EIP-1559
+// transaction in its canonical (RLP / typed envelope) encoding.
+func DecodeRawTransaction(raw []byte) (*types.Transaction, error) {
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(raw); err != nil {
+		return nil, errors.WithMessage(err, "simulation: decode raw transaction")
+	}
+	return tx, nil
+}
+
+// TransactionToCallArgs recovers the sender of tx for chainID and converts
+// the transaction into eth_call arguments.
+func TransactionToCallArgs(tx *types.Transaction, chainID *big.Int) (CallArgs, error) {
+	from, err := types.Sender(types.LatestSignerForChainID(chainID), tx)
+	if err != nil {
+		return CallArgs{}, errors.WithMessage(err, "simulation: recover sender")
+	}
+
+	gas := hexutil.Uint64(tx.Gas())
+	nonce := hexutil.Uint64(tx.Nonce())
+	data := hexutil.Bytes(tx.Data())
+	args := CallArgs{
+		From:  &from,
+		To:    tx.To(),
+		Gas:   &gas,
+		Value: (*hexutil.Big)(tx.Value()),
+		Nonce: &nonce,
+		Data:  &data,
+	}
+	switch tx.Type() {
+	case types.DynamicFeeTxType:
+		args.MaxFeePerGas = (*hexutil.Big)(tx.GasFeeCap())
+		args.MaxPriorityFeePerGas = (*hexutil.Big)(tx.GasTipCap())
+	default:
+		args.GasPrice = (*hexutil.Big)(tx.GasPrice())
+	}
+	if tx.Type() != types.LegacyTxType {
+		accessList := tx.AccessList()
+		args.AccessList = &accessList
+		args.ChainID = (*hexutil.Big)(tx.ChainId())
+	}
+	return args, nil
+}
+
+// SimulateRawTransaction decodes a signed raw transaction and simulates it at
+// blockNumber. When chainID is nil it is queried from the node. With
+// validateNonce set, the transaction nonce must equal the sender's nonce at
+// blockNumber, which eth_call itself does not check.
+func (s *Simulator) SimulateRawTransaction(ctx context.Context, raw []byte, chainID *big.Int, blockNumber *big.Int, validateNonce bool) (*Result, error) {
+	tx, err := DecodeRawTransaction(raw)
+	if err != nil {
+		return nil, err
+	}
+	if chainID == nil {
+		var id hexutil.Big
+		if err := s.client.CallContext(ctx, &id, "eth_chainId"); err != nil {
+			return nil, errors.WithMessage(err, "simulation: eth_chainId")
+		}
+		chainID = (*big.Int)(&id)
+	}
+	args, err := TransactionToCallArgs(tx, chainID)
+	if err != nil {
+		return nil, err
+	}
+
+	if validateNonce {
+		var nonce hexutil.Uint64
+		if err := s.client.CallContext(ctx, &nonce, "eth_getTransactionCount", args.From, toBlockNumArg(blockNumber)); err != nil {
+			return nil, errors.WithMessage(err, "simulation: eth_getTransactionCount")
+		}
+		switch {
+		case tx.Nonce() < uint64(nonce):
+			return nil, errors.Wrapf(ErrNonceTooLow, "simulation: tx nonce %d, state nonce %d", tx.Nonce(), nonce)
+		case tx.Nonce() > uint64(nonce):
+			return nil, errors.Wrapf(ErrNonceTooHigh, "simulation: tx nonce %d, state nonce %d", tx.Nonce(), nonce)
+		}
+	}
+
+	return s.Simulate(ctx, args, blockNumber)
+}
@@ -0,0 +1,255 @@
+package simulation
+
+import (
+	"encoding/json"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+	"io/ioutil"
+	"math/big"
+	"sort"
+	"sync"
+)
+
+// RegistryVersion is the registry file format this package reads and writes.
+const RegistryVersion = 1
+
+// Layout is how a token stores its balances and allowances.
+type Layout string
+
+const (
+	// LayoutSolidity mappings live at keccak256(key . slot).
+	LayoutSolidity Layout = "solidity"
+	// LayoutVyper mappings live at keccak256(slot . key).
+	LayoutVyper Layout = "vyper"
+	// LayoutPacked balances share their slot with other data and cannot be
+	// overridden by writing a whole slot.
+	LayoutPacked Layout = "packed"
+)
+
+var (
+	ErrTokenNotRegistered = errors.New("simulation: token not in registry")
+	ErrPackedLayout       = errors.New("simulation: packed layout cannot be overridden")
+	ErrNoSlot             = errors.New("simulation: slot not registered")
+)
+
+// Proxy describes where a proxied token's logic lives. The slots of a proxy
+// are those of its own storage, as read through the proxy.
+type Proxy struct {
+	Kind           string         `json:"kind"`
+	Implementation common.Address `json:"implementation"`
+}
+
+// TokenLayout is a registry entry. Nil slots are unknown.
+type TokenLayout struct {
+	ChainID       uint64         `json:"chainId"`
+	Token         common.Address `json:"token"`
+	Symbol        string         `json:"symbol,omitempty"`
+	Decimals      uint8          `json:"decimals"`
+	Layout        Layout         `json:"layout,omitempty"`
+	BalanceSlot   *uint64        `json:"balanceSlot,omitempty"`
+	AllowanceSlot *uint64        `json:"allowanceSlot,omitempty"`
+	Proxy         *Proxy         `json:"proxy,omitempty"`
+}
+
+// BalanceKey is the storage key of owner's balance.
+func (l *TokenLayout) BalanceKey(owner common.Address) (common.Hash, error) {
+	if l.BalanceSlot == nil {
+		return common.Hash{}, ErrNoSlot
+	}
+	return l.mappingKey(owner, new(big.Int).SetUint64(*l.BalanceSlot))
+}
+
+// AllowanceKey is the storage key of owner's allowance for spender.
+func (l *TokenLayout) AllowanceKey(owner, spender common.Address) (common.Hash, error) {
+	if l.AllowanceSlot == nil {
+		return common.Hash{}, ErrNoSlot
+	}
+	inner, err := l.mappingKey(owner, new(big.Int).SetUint64(*l.AllowanceSlot))
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return l.mappingKey(spender, inner.Big())
+}
+
+func (l *TokenLayout) mappingKey(key common.Address, slot *big.Int) (common.Hash, error) {
+	switch l.Layout {
+	case LayoutSolidity, "":
+		return BalanceKey(key, slot), nil
+	case LayoutVyper:
+		return crypto.Keccak256Hash(common.BigToHash(slot).Bytes(), common.LeftPadBytes(key.Bytes(), 32)), nil
+	case LayoutPacked:
+		return common.Hash{}, ErrPackedLayout
+	}
+	return common.Hash{}, errors.Errorf("simulation: unknown layout %q", l.Layout)
+}
+
+// merge sets the fields other knows on top of l.
+func (l TokenLayout) merge(other TokenLayout) TokenLayout {
+	if other.Symbol != "" {
+		l.Symbol = other.Symbol
+	}
+	if other.Decimals != 0 {
+		l.Decimals = other.Decimals
+	}
+	if other.Layout != "" {
+		l.Layout = other.Layout
+	}
+	if other.BalanceSlot != nil {
+		l.BalanceSlot = other.BalanceSlot
+	}
+	if other.AllowanceSlot != nil {
+		l.AllowanceSlot = other.AllowanceSlot
+	}
+	if other.Proxy != nil {
+		l.Proxy = other.Proxy
+	}
+	return l
+}
+
+type registryKey struct {
+	chainID uint64
+	token   common.Address
+}
+
+// Registry maps chain and token to storage layouts. It is safe for
+// concurrent use.
+type Registry struct {
+	mu     sync.RWMutex
+	tokens map[registryKey]TokenLayout
+}
+
+type registryFile struct {
+	Version int           `json:"version"`
+	Tokens  []TokenLayout `json:"tokens"`
+}
+
+func NewRegistry() *Registry {
+	return &Registry{tokens: make(map[registryKey]TokenLayout)}
+}
+
+// LoadRegistry reads a registry file.
+func LoadRegistry(path string) (*Registry, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.WithMessage(err, "simulation: read registry")
+	}
+	return ParseRegistry(data)
+}
+
+func ParseRegistry(data []byte) (*Registry, error) {
+	var file registryFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, errors.WithMessage(err, "simulation: parse registry")
+	}
+	if file.Version != RegistryVersion {
+		return nil, errors.Errorf("simulation: registry version %d, want %d", file.Version, RegistryVersion)
+	}
+	r := NewRegistry()
+	for _, token := range file.Tokens {
+		r.Put(token)
+	}
+	return r, nil
+}
+
+// Save writes the registry, sorted by chain and token so diffs stay small.
+func (r *Registry) Save(path string) error {
+	data, err := r.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return errors.WithMessage(err, "simulation: write registry")
+	}
+	return nil
+}
+
+func (r *Registry) MarshalJSON() ([]byte, error) {
+	file := registryFile{Version: RegistryVersion, Tokens: r.Tokens()}
+	data, err := json.MarshalIndent(file, "", "\t")
+	if err != nil {
+		return nil, errors.WithMessage(err, "simulation: marshal registry")
+	}
+	return data, nil
+}
+
+// Tokens returns all entries sorted by chain and token.
+func (r *Registry) Tokens() []TokenLayout {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	tokens := make([]TokenLayout, 0, len(r.tokens))
+	for _, token := range r.tokens {
+		tokens = append(tokens, token)
+	}
+	sort.Slice(tokens, func(i, j int) bool {
+		if tokens[i].ChainID != tokens[j].ChainID {
+			return tokens[i].ChainID < tokens[j].ChainID
+		}
+		return tokens[i].Token.Hex() < tokens[j].Token.Hex()
+	})
+	return tokens
+}
+
+func (r *Registry) Lookup(chainID uint64, token common.Address) (TokenLayout, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	layout, ok := r.tokens[registryKey{chainID, token}]
+	return layout, ok
+}
+
+// Put adds layout, merging it into an existing entry: the fields layout
+// sets replace the registered ones.
+func (r *Registry) Put(layout TokenLayout) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := registryKey{layout.ChainID, layout.Token}
+	if existing, ok := r.tokens[key]; ok {
+		layout = existing.merge(layout)
+	}
+	r.tokens[key] = layout
+}
+
+// Merge puts every entry of other into r.
+func (r *Registry) Merge(other *Registry) {
+	for _, layout := range other.Tokens() {
+		r.Put(layout)
+	}
+}
+
+// FundOverrides sets owner's balance of token to amount.
+func (r *Registry) FundOverrides(chainID uint64, token, owner common.Address, amount *big.Int) (OverrideAccounts, error) {
+	layout, ok := r.Lookup(chainID, token)
+	if !ok {
+		return nil, ErrTokenNotRegistered
+	}
+	key, err := layout.BalanceKey(owner)
+	if err != nil {
+		return nil, errors.WithMessage(err, token.Hex())
+	}
+	return OverrideAccounts{token: {StateDiff: map[string]string{key.Hex(): common.BigToHash(amount).Hex()}}}, nil
+}
+
+// ApproveOverrides sets owner's allowance of token for spender to amount.
+func (r *Registry) ApproveOverrides(chainID uint64, token, owner, spender common.Address, amount *big.Int) (OverrideAccounts, error) {
+	layout, ok := r.Lookup(chainID, token)
+	if !ok {
+		return nil, ErrTokenNotRegistered
+	}
+	key, err := layout.AllowanceKey(owner, spender)
+	if err != nil {
+		return nil, errors.WithMessage(err, token.Hex())
+	}
+	return OverrideAccounts{token: {StateDiff: map[string]string{key.Hex(): common.BigToHash(amount).Hex()}}}, nil
+}
+
+// BalanceSlots returns the Solidity balance slots of chainID, for
+// SwapRequest.BalanceSlots.
+func (r *Registry) BalanceSlots(chainID uint64) map[common.Address]*big.Int {
+	slots := make(map[common.Address]*big.Int)
+	for _, layout := range r.Tokens() {
+		if layout.ChainID == chainID && layout.BalanceSlot != nil && (layout.Layout == LayoutSolidity || layout.Layout == "") {
+			slots[layout.Token] = new(big.Int).SetUint64(*layout.BalanceSlot)
+		}
+	}
+	return slots
+}
@@ -0,0 +1,246 @@
+package simulation
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+	"math/big"
+)
+
+// prestateAccount is an entry of the native prestateTracer output.
+type prestateAccount struct {
+	Balance string                      `json:"balance"`
+	Nonce   uint64                      `json:"nonce"`
+	Code    string                      `json:"code"`
+	Storage map[common.Hash]common.Hash `json:"storage"`
+}
+
+// ReplayResult compares the simulation of a mined transaction with its receipt.
+type ReplayResult struct {
+	Tx          *types.Transaction
+	Receipt     *types.Receipt
+	BlockNumber *big.Int
+	// PrestateApplied reports whether the state left by the preceding
+	// transactions of the block was applied on top of the parent state.
+	PrestateApplied bool
+	// FeesDropped reports whether the fee fields had to be removed because
+	// the parent block's base fee is above the transaction's fee cap.
+	FeesDropped bool
+	Simulated   *TraceResult
+	Diffs       []string
+}
+
+// Match reports whether the simulation reproduced the receipt.
+func (r *ReplayResult) Match() bool {
+	return len(r.Diffs) == 0
+}
+
+// Replay re-simulates the mined transaction txHash on the state of its
+// parent block with the block context of the block it was mined in. When the
+// node serves debug_traceTransaction, the state the transaction read after the
+// preceding in-block transactions is applied as overrides; otherwise the
+// transaction runs on the bare parent state. The simulated status, gas and
+// logs are then compared with the receipt.
+func (s *Simulator) Replay(ctx context.Context, txHash common.Hash) (*ReplayResult, error) {
+	env, err := s.loadTransaction(ctx, txHash)
+	if err != nil {
+		return nil, err
+	}
+	if env.receipt == nil {
+		return nil, errors.Errorf("simulation: transaction %s is pending", txHash)
+	}
+
+	res := &ReplayResult{
+		Tx:              env.tx,
+		Receipt:         env.receipt,
+		BlockNumber:     env.receipt.BlockNumber,
+		PrestateApplied: env.prestateApplied,
+		FeesDropped:     env.feesDropped,
+	}
+	res.Simulated, err = env.sim.Trace(ctx, env.args, env.blockNumber, env.blockOverrides)
+	if err != nil {
+		return nil, err
+	}
+	res.Diffs = diffReceipt(env.receipt, res.Simulated)
+	return res, nil
+}
+
+// txEnvironment holds what is needed to re-execute a transaction where it ran.
+type txEnvironment struct {
+	tx      *types.Transaction
+	receipt *types.Receipt // nil while the transaction is pending
+	args    CallArgs
+
+	sim            *Simulator
+	blockNumber    *big.Int
+	blockOverrides *BlockOverrides
+
+	prestateApplied bool
+	feesDropped     bool
+}
+
+// loadTransaction fetches txHash and prepares its re-execution. A pending
+// transaction runs on the latest state. A mined one runs on its parent block
+// with the mined block context and, where the node allows it, the state left
+// by the preceding in-block transactions.
+func (s *Simulator) loadTransaction(ctx context.Context, txHash common.Hash) (*txEnvironment, error) {
+	var raw json.RawMessage
+	if err := s.client.CallContext(ctx, &raw, "eth_getTransactionByHash", txHash); err != nil {
+		return nil, errors.WithMessage(err, "simulation: get transaction")
+	}
+	if isNull(raw) {
+		return nil, errors.WithMessage(ethereum.NotFound, "simulation: get transaction")
+	}
+	tx := new(types.Transaction)
+	if err := json.Unmarshal(raw, tx); err != nil {
+		return nil, errors.WithMessage(err, "simulation: decode transaction")
+	}
+	// Mined transactions carry their block number.
+	var extra struct {
+		BlockNumber *string `json:"blockNumber"`
+	}
+	json.Unmarshal(raw, &extra)
+	pending := extra.BlockNumber == nil
+	var chainID hexutil.Big
+	if err := s.client.CallContext(ctx, &chainID, "eth_chainId"); err != nil {
+		return nil, errors.WithMessage(err, "simulation: get chain id")
+	}
+	args, err := TransactionToCallArgs(tx, chainID.ToInt())
+	if err != nil {
+		return nil, err
+	}
+	env := &txEnvironment{
+		tx:   tx,
+		args: args,
+		sim:  s,
+	}
+	if pending {
+		return env, nil
+	}
+
+	if err := s.client.CallContext(ctx, &env.receipt, "eth_getTransactionReceipt", txHash); err != nil || env.receipt == nil {
+		return nil, errors.WithMessage(orNotFound(err), "simulation: get receipt")
+	}
+	header, err := s.headerByHash(ctx, env.receipt.BlockHash)
+	if err != nil {
+		return nil, errors.WithMessage(err, "simulation: get block")
+	}
+	parent, err := s.headerByHash(ctx, header.ParentHash)
+	if err != nil {
+		return nil, errors.WithMessage(err, "simulation: get parent block")
+	}
+	env.blockNumber = parent.Number
+
+	// The call runs with the parent's base fee, which may exceed a fee cap
+	// that was valid for the mined block.
+	if parent.BaseFee != nil && tx.GasFeeCap().Cmp(parent.BaseFee) < 0 {
+		env.args.GasPrice, env.args.MaxFeePerGas, env.args.MaxPriorityFeePerGas = nil, nil, nil
+		env.feesDropped = true
+	}
+
+	if prestate, err := s.prestate(ctx, txHash); err == nil {
+		env.sim = s.WithOverrides(prestate)
+		env.prestateApplied = true
+	}
+
+	gasLimit := hexutil.Uint64(header.GasLimit)
+	env.blockOverrides = &BlockOverrides{
+		Number:     (*hexutil.Big)(header.Number),
+		Difficulty: (*hexutil.Big)(header.Difficulty),
+		Time:       (*hexutil.Big)(new(big.Int).SetUint64(header.Time)),
+		GasLimit:   &gasLimit,
+		Coinbase:   &header.Coinbase,
+	}
+	if header.Difficulty.Sign() == 0 {
+		env.blockOverrides.Difficulty = nil
+		env.blockOverrides.Random = &header.MixDigest
+	}
+	return env, nil
+}
+
+func (s *Simulator) headerByHash(ctx context.Context, hash common.Hash) (*types.Header, error) {
+	var header *types.Header
+	if err := s.client.CallContext(ctx, &header, "eth_getBlockByHash", hash, false); err != nil || header == nil {
+		return nil, orNotFound(err)
+	}
+	return header, nil
+}
+
+// orNotFound is err, or ethereum.NotFound for a null result.
+func orNotFound(err error) error {
+	if err == nil {
+		return ethereum.NotFound
+	}
+	return err
+}
+
+// prestate returns the accounts and storage txHash read, as they were right
+// before it executed, in override form.
+func (s *Simulator) prestate(ctx context.Context, txHash common.Hash) (OverrideAccounts, error) {
+	var accounts map[common.Address]prestateAccount
+	config := map[string]string{"tracer": "prestateTracer"}
+	if err := s.client.CallContext(ctx, &accounts, "debug_traceTransaction", txHash, config); err != nil {
+		return nil, errors.WithMessage(err, "simulation: prestate trace")
+	}
+
+	overrides := make(OverrideAccounts, len(accounts))
+	for addr, acc := range accounts {
+		// Code is left to the parent state: contracts deployed earlier in the
+		// same block are rare and code would dominate the request size.
+		override := Account{
+			Nonce:   hexutil.EncodeUint64(acc.Nonce),
+			Balance: acc.Balance,
+		}
+		if len(acc.Storage) > 0 {
+			override.StateDiff = make(map[string]string, len(acc.Storage))
+			for key, value := range acc.Storage {
+				override.StateDiff[key.Hex()] = value.Hex()
+			}
+		}
+		overrides[addr] = override
+	}
+	return overrides, nil
+}
+
+func diffReceipt(receipt *types.Receipt, sim *TraceResult) []string {
+	var diffs []string
+	if succeeded := !sim.Failed; succeeded != (receipt.Status == types.ReceiptStatusSuccessful) {
+		diffs = append(diffs, fmt.Sprintf("status: receipt %d, simulated success=%t (%s)", receipt.Status, succeeded, sim.Error))
+	}
+	if receipt.GasUsed != sim.GasUsed {
+		diffs = append(diffs, fmt.Sprintf("gasUsed: receipt %d, simulated %d", receipt.GasUsed, sim.GasUsed))
+	}
+	if len(receipt.Logs) != len(sim.Logs) {
+		diffs = append(diffs, fmt.Sprintf("logs: receipt %d, simulated %d", len(receipt.Logs), len(sim.Logs)))
+	}
+	for i := 0; i < len(receipt.Logs) && i < len(sim.Logs); i++ {
+		want, got := receipt.Logs[i], sim.Logs[i]
+		switch {
+		case want.Address != got.Address:
+			diffs = append(diffs, fmt.Sprintf("log %d address: receipt %s, simulated %s", i, want.Address, got.Address))
+		case !equalTopics(want.Topics, got.Topics):
+			diffs = append(diffs, fmt.Sprintf("log %d topics: receipt %v, simulated %v", i, want.Topics, got.Topics))
+		case !bytes.Equal(want.Data, got.Data):
+			diffs = append(diffs, fmt.Sprintf("log %d data: receipt %x, simulated %x", i, want.Data, got.Data))
+		}
+	}
+	return diffs
+}
+
+func equalTopics(a, b []common.Hash) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
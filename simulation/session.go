@@ -0,0 +1,184 @@
+package simulation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"geth/rpcpool"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/pkg/errors"
+)
+
+// sessionBlockParam is the index of the block parameter of the methods a
+// Session pins.
+var sessionBlockParam = map[string]int{
+	"eth_call":                1,
+	"eth_estimateGas":         1,
+	"eth_createAccessList":    1,
+	"debug_traceCall":         1,
+	"eth_getBalance":          1,
+	"eth_getCode":             1,
+	"eth_getTransactionCount": 1,
+	"eth_getStorageAt":        2,
+	"eth_getProof":            2,
+}
+
+// ReorgError reports that the block a Session is pinned to left the
+// canonical chain.
+type ReorgError struct {
+	Number    uint64
+	Pinned    common.Hash
+	Canonical common.Hash
+}
+
+func (e *ReorgError) Error() string {
+	return fmt.Sprintf("simulation: block %d reorged: pinned %s, canonical %s", e.Number, e.Pinned.Hex(), e.Canonical.Hex())
+}
+
+// Session is a Client that resolves "latest" once and runs every later state
+// read, call and trace against that block by hash, so the steps of one
+// analysis agree with each other. Calls naming another block or "pending"
+// pass through. A call failing because the block was reorged out returns a
+// *ReorgError; over a cache, calls it answers do not fail, see CheckReorg.
+type Session struct {
+	client Client
+	block  *blockID
+}
+
+// blockID is the part of a block a Session needs. The hash is taken as the
+// node reports it rather than recomputed from header fields this geth
+// version may not know.
+type blockID struct {
+	Number hexutil.Uint64 `json:"number"`
+	Hash   common.Hash    `json:"hash"`
+	Time   hexutil.Uint64 `json:"timestamp"`
+}
+
+// NewSession pins the latest block of client.
+func NewSession(ctx context.Context, client Client) (*Session, error) {
+	var block *blockID
+	if err := client.CallContext(ctx, &block, "eth_getBlockByNumber", "latest", false); err != nil || block == nil {
+		return nil, errors.WithMessage(orNotFound(err), "simulation: get latest block")
+	}
+	return &Session{client: client, block: block}, nil
+}
+
+func (s *Session) Number() uint64 {
+	return uint64(s.block.Number)
+}
+
+func (s *Session) Hash() common.Hash {
+	return s.block.Hash
+}
+
+// Time is the pinned block's timestamp.
+func (s *Session) Time() uint64 {
+	return uint64(s.block.Time)
+}
+
+// Simulator returns a Simulator running against the pinned block.
+func (s *Session) Simulator(overrides OverrideAccounts) *Simulator {
+	return NewSimulator(s, overrides)
+}
+
+// StateReader returns a StateReader reading the pinned block.
+func (s *Session) StateReader() *StateReader {
+	return NewStateReader(s)
+}
+
+// CheckReorg returns a *ReorgError if the pinned block is no longer the
+// canonical block at its height.
+func (s *Session) CheckReorg(ctx context.Context) error {
+	var canonical *blockID
+	if err := s.client.CallContext(ctx, &canonical, "eth_getBlockByNumber", s.block.Number, false); err != nil {
+		return errors.WithMessage(err, "simulation: check reorg")
+	}
+	if canonical == nil || canonical.Hash != s.Hash() {
+		reorg := &ReorgError{Number: s.Number(), Pinned: s.Hash()}
+		if canonical != nil {
+			reorg.Canonical = canonical.Hash
+		}
+		return reorg
+	}
+	return nil
+}
+
+func (s *Session) CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error {
+	switch method {
+	case "eth_blockNumber":
+		return setResult(result, hexutil.Uint64(s.Number()))
+	case "eth_getBlockByNumber":
+		if len(args) > 0 && isLatest(args[0]) {
+			method, args = "eth_getBlockByHash", append([]interface{}{s.Hash()}, args[1:]...)
+		}
+	default:
+		args = s.pin(method, args)
+	}
+	err := s.client.CallContext(ctx, result, method, args...)
+	if err != nil && !rpcpool.IsExecutionError(err) {
+		if reorg, ok := s.CheckReorg(ctx).(*ReorgError); ok {
+			return reorg
+		}
+	}
+	return err
+}
+
+// BatchCallContext pins the calls of b like CallContext. eth_blockNumber and
+// eth_getBlockByNumber are sent as they are.
+func (s *Session) BatchCallContext(ctx context.Context, b []rpc.BatchElem) error {
+	for i := range b {
+		b[i].Args = s.pin(b[i].Method, b[i].Args)
+	}
+	err := s.client.BatchCallContext(ctx, b)
+	failed := err != nil
+	for _, elem := range b {
+		failed = failed || elem.Error != nil && !rpcpool.IsExecutionError(elem.Error)
+	}
+	if failed {
+		if reorg, ok := s.CheckReorg(ctx).(*ReorgError); ok {
+			return reorg
+		}
+	}
+	return err
+}
+
+// pin replaces a missing or "latest" block parameter with the pinned hash.
+func (s *Session) pin(method string, args []interface{}) []interface{} {
+	i, ok := sessionBlockParam[method]
+	if !ok || len(args) < i || len(args) > i && !isLatest(args[i]) {
+		return args
+	}
+	pinned := make([]interface{}, len(args))
+	copy(pinned, args)
+	block := map[string]interface{}{"blockHash": s.Hash(), "requireCanonical": true}
+	if len(pinned) == i {
+		return append(pinned, block)
+	}
+	pinned[i] = block
+	return pinned
+}
+
+// isLatest reports whether a block parameter is "latest" or left out.
+func isLatest(block interface{}) bool {
+	if block == nil {
+		return true
+	}
+	data, err := json.Marshal(block)
+	if err != nil {
+		return false
+	}
+	return string(data) == `"latest"` || string(data) == "null"
+}
+
+func setResult(result interface{}, value interface{}) error {
+	if result == nil {
+		return nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, result)
+}
@@ -0,0 +1,286 @@
+package simulation
+
+import (
+	"context"
+	"fmt"
+	"geth/contract/simswap"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/pkg/errors"
+	"math/big"
+	"strings"
+)
+
+// SimSwapAddress is where the SimSwap code is injected by SimulateSwap.
+var SimSwapAddress = common.HexToAddress("0x1111111111111111111111111111111111111100")
+
+var (
+	simswapAbi abi.ABI
+
+	panicSelector = hexutil.MustDecode("0x4e487b71")
+)
+
+func init() {
+	var err error
+	simswapAbi, err = abi.JSON(strings.NewReader(simswap.ContractMetaData.ABI))
+	if err != nil {
+		panic(err)
+	}
+}
+
+// SwapRequest is a router call to run through SimSwap on behalf of From.
+type SwapRequest struct {
+	From     common.Address
+	TokenIn  common.Address
+	TokenOut common.Address
+	Router   common.Address
+	Data     []byte
+	// Value is the ETH sent along, required when TokenIn is the native token.
+	Value *big.Int
+	Gas   uint64
+	// BalanceSlots maps tokens to the slot of their balances mapping. TokenIn
+	// and TokenOut found here are analysed with AnalyzeToken and the reports
+	// attached to the result.
+	BalanceSlots map[common.Address]*big.Int
+}
+
+// SwapResult is what SimSwap reports about a swap.
+type SwapResult struct {
+	AmountIn  *big.Int
+	AmountOut *big.Int
+	// GasUsed is the gas the router call consumed, excluding the SimSwap
+	// bookkeeping around it.
+	GasUsed uint64
+	Success bool
+	// ReturnData is the router's output, or its revert data when Success is
+	// false.
+	ReturnData   []byte
+	RevertReason string
+
+	// TokenIn and TokenOut are set for tokens with a known balance slot, see
+	// SwapRequest.BalanceSlots.
+	TokenIn  *TokenReport
+	TokenOut *TokenReport
+}
+
+func (r *SwapResult) String() string {
+	if !r.Success {
+		return fmt.Sprintf("swap would fail: %s, gas %d", r.RevertReason, r.GasUsed)
+	}
+	s := fmt.Sprintf("swap in %s out %s, gas %d", r.AmountIn, r.AmountOut, r.GasUsed)
+	for _, report := range []*TokenReport{r.TokenIn, r.TokenOut} {
+		if report != nil && report.Behaviour != TokenStandard {
+			s += ", " + report.String()
+		}
+	}
+	return s
+}
+
+// SimulateSwap runs req through the SimSwap contract, injected at
+// SimSwapAddress, in a single eth_call. A failing router call is reported in
+// the result rather than as an error.
+func (s *Simulator) SimulateSwap(ctx context.Context, req SwapRequest, blockNumber *big.Int) (*SwapResult, error) {
+	data, err := simswapAbi.Pack("simswap", req.TokenIn, req.TokenOut, req.Router, req.Data)
+	if err != nil {
+		return nil, errors.WithMessage(err, "simulation: pack simswap")
+	}
+	input := hexutil.Bytes(data)
+	args := CallArgs{
+		From:  &req.From,
+		To:    &SimSwapAddress,
+		Value: (*hexutil.Big)(req.Value),
+		Data:  &input,
+	}
+	if req.Gas != 0 {
+		gas := hexutil.Uint64(req.Gas)
+		args.Gas = &gas
+	}
+
+	sim := s.WithOverrides(OverrideAccounts{SimSwapAddress: {Code: simswap.RuntimeCode}})
+	res, err := sim.Simulate(ctx, args, blockNumber)
+	if err != nil {
+		return nil, err
+	}
+	if res.Failed {
+		// SimSwap itself reverted, e.g. on missing balance or allowance.
+		return nil, errors.Errorf("simulation: simswap reverted: %s", res.RevertReason)
+	}
+	out, err := UnpackSwapResult(res.ReturnData)
+	if err != nil {
+		return nil, err
+	}
+
+	// Analyse with the swapped amounts so that amount dependent fees and
+	// limits show up.
+	if slot, ok := req.BalanceSlots[req.TokenIn]; ok {
+		out.TokenIn, err = s.AnalyzeToken(ctx, req.TokenIn, slot, analysisAmount(out.AmountIn), blockNumber)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if slot, ok := req.BalanceSlots[req.TokenOut]; ok {
+		out.TokenOut, err = s.AnalyzeToken(ctx, req.TokenOut, slot, analysisAmount(out.AmountOut), blockNumber)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// analysisAmount falls back to 1e18 when a swap moved nothing.
+func analysisAmount(amount *big.Int) *big.Int {
+	if amount.Sign() > 0 {
+		return amount
+	}
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil)
+}
+
+// UnpackSwapResult decodes the return data of SimSwap.simswap.
+func UnpackSwapResult(ret []byte) (*SwapResult, error) {
+	out, err := simswapAbi.Unpack("simswap", ret)
+	if err != nil {
+		return nil, errors.WithMessage(err, "simulation: unpack simswap")
+	}
+	res := &SwapResult{
+		AmountIn:   out[0].(*big.Int),
+		AmountOut:  out[1].(*big.Int),
+		GasUsed:    out[2].(*big.Int).Uint64(),
+		Success:    out[3].(bool),
+		ReturnData: out[4].([]byte),
+	}
+	if !res.Success {
+		res.RevertReason = DecodeRevert(res.ReturnData)
+	}
+	return res, nil
+}
+
+// MultiSwapRequest is a router call to run through SimSwap.simswapMulti on
+// behalf of From, watching the balances of Tokens.
+type MultiSwapRequest struct {
+	From   common.Address
+	Tokens []common.Address
+	Router common.Address
+	Data   []byte
+	Value  *big.Int
+	Gas    uint64
+}
+
+// MultiSwapResult holds the signed balance change of From for each requested
+// token, in request order.
+type MultiSwapResult struct {
+	Tokens       []common.Address
+	Deltas       []*big.Int
+	GasUsed      uint64
+	Success      bool
+	ReturnData   []byte
+	RevertReason string
+}
+
+// Delta returns the balance change of token, nil if it was not watched.
+func (r *MultiSwapResult) Delta(token common.Address) *big.Int {
+	for i, t := range r.Tokens {
+		if t == token {
+			return r.Deltas[i]
+		}
+	}
+	return nil
+}
+
+// Changed returns the tokens whose balance changed, excluding the ones in
+// except. With the in and out tokens as except, the result lists leftovers
+// and refunds.
+func (r *MultiSwapResult) Changed(except ...common.Address) []common.Address {
+	skip := make(map[common.Address]bool, len(except))
+	for _, token := range except {
+		skip[token] = true
+	}
+	var changed []common.Address
+	for i, token := range r.Tokens {
+		if r.Deltas[i].Sign() != 0 && !skip[token] {
+			changed = append(changed, token)
+		}
+	}
+	return changed
+}
+
+func (r *MultiSwapResult) String() string {
+	if !r.Success {
+		return fmt.Sprintf("swap would fail: %s, gas %d", r.RevertReason, r.GasUsed)
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "swap gas %d", r.GasUsed)
+	for i, token := range r.Tokens {
+		fmt.Fprintf(&b, ", %s %s", token.Hex(), r.Deltas[i])
+	}
+	return b.String()
+}
+
+// SimulateSwapMulti is SimulateSwap measuring the balance change of every
+// token in req.Tokens, including intermediary ones.
+func (s *Simulator) SimulateSwapMulti(ctx context.Context, req MultiSwapRequest, blockNumber *big.Int) (*MultiSwapResult, error) {
+	data, err := simswapAbi.Pack("simswapMulti", req.Tokens, req.Router, req.Data)
+	if err != nil {
+		return nil, errors.WithMessage(err, "simulation: pack simswapMulti")
+	}
+	input := hexutil.Bytes(data)
+	args := CallArgs{
+		From:  &req.From,
+		To:    &SimSwapAddress,
+		Value: (*hexutil.Big)(req.Value),
+		Data:  &input,
+	}
+	if req.Gas != 0 {
+		gas := hexutil.Uint64(req.Gas)
+		args.Gas = &gas
+	}
+
+	sim := s.WithOverrides(OverrideAccounts{SimSwapAddress: {Code: simswap.RuntimeCode}})
+	res, err := sim.Simulate(ctx, args, blockNumber)
+	if err != nil {
+		return nil, err
+	}
+	if res.Failed {
+		return nil, errors.Errorf("simulation: simswapMulti reverted: %s", res.RevertReason)
+	}
+	out, err := UnpackSwapMultiResult(res.ReturnData)
+	if err != nil {
+		return nil, err
+	}
+	out.Tokens = req.Tokens
+	return out, nil
+}
+
+// UnpackSwapMultiResult decodes the return data of SimSwap.simswapMulti.
+// Tokens is left empty; the deltas follow the order of the call's tokens.
+func UnpackSwapMultiResult(ret []byte) (*MultiSwapResult, error) {
+	out, err := simswapAbi.Unpack("simswapMulti", ret)
+	if err != nil {
+		return nil, errors.WithMessage(err, "simulation: unpack simswapMulti")
+	}
+	res := &MultiSwapResult{
+		Deltas:     out[0].([]*big.Int),
+		GasUsed:    out[1].(*big.Int).Uint64(),
+		Success:    out[2].(bool),
+		ReturnData: out[3].([]byte),
+	}
+	if !res.Success {
+		res.RevertReason = DecodeRevert(res.ReturnData)
+	}
+	return res, nil
+}
+
+// DecodeRevert renders revert data: Error(string) reasons and Panic(uint256)
+// codes are decoded, anything else (custom errors) is returned as hex.
+func DecodeRevert(data []byte) string {
+	if reason, err := abi.UnpackRevert(data); err == nil {
+		return reason
+	}
+	if len(data) == 36 && string(data[:4]) == string(panicSelector) {
+		return fmt.Sprintf("panic 0x%x", new(big.Int).SetBytes(data[4:]))
+	}
+	if len(data) == 0 {
+		return "reverted without reason"
+	}
+	return hexutil.Encode(data)
+}
@@ -0,0 +1,142 @@
+package simulation
+
+import (
+	"context"
+	"geth/rpcpool"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/pkg/errors"
+	"math/big"
+	"strings"
+)
+
+// CallArgs are the transaction arguments accepted by eth_call.
+type CallArgs struct {
+	From                 *common.Address `json:"from"`
+	To                   *common.Address `json:"to"`
+	Gas                  *hexutil.Uint64 `json:"gas,omitempty"`
+	GasPrice             *hexutil.Big    `json:"gasPrice,omitempty"`
+	MaxFeePerGas         *hexutil.Big    `json:"maxFeePerGas,omitempty"`
+	MaxPriorityFeePerGas *hexutil.Big    `json:"maxPriorityFeePerGas,omitempty"`
+	Value                *hexutil.Big    `json:"value,omitempty"`
+	Nonce                *hexutil.Uint64 `json:"nonce,omitempty"`
+	Data                 *hexutil.Bytes  `json:"data,omitempty"`
+
+	AccessList *types.AccessList `json:"accessList,omitempty"`
+	ChainID    *hexutil.Big      `json:"chainId,omitempty"`
+}
+
+// Result is the outcome of a single simulated call.
+type Result struct {
+	ReturnData   []byte
+	Failed       bool
+	RevertReason string
+}
+
+// Client is a node connection: an *rpc.Client, or a pool spreading calls
+// over several nodes.
+type Client interface {
+	CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error
+	BatchCallContext(ctx context.Context, b []rpc.BatchElem) error
+}
+
+// Simulator runs eth_call against a node with a fixed set of state overrides.
+type Simulator struct {
+	client    Client
+	overrides OverrideAccounts
+}
+
+func NewSimulator(client Client, overrides OverrideAccounts) *Simulator {
+	return &Simulator{
+		client:    client,
+		overrides: overrides,
+	}
+}
+
+func Dial(rawurl string, overrides OverrideAccounts) (*Simulator, error) {
+	c, err := rpc.Dial(rawurl)
+	if err != nil {
+		return nil, errors.WithMessage(err, "simulation: dial rpc")
+	}
+	return NewSimulator(c, overrides), nil
+}
+
+// WithOverrides returns a Simulator sharing the same connection whose
+// overrides are the current ones with extra merged on top.
+func (s *Simulator) WithOverrides(extra OverrideAccounts) *Simulator {
+	return NewSimulator(s.client, s.overrides.Merge(extra))
+}
+
+// Simulate executes args at blockNumber (nil means latest). A call that
+// reverts or fails in the EVM is not an error: it is reported through
+// Result.Failed and Result.RevertReason. Errors are the node's.
+func (s *Simulator) Simulate(ctx context.Context, args CallArgs, blockNumber *big.Int) (*Result, error) {
+	var hex hexutil.Bytes
+	err := s.client.CallContext(ctx, &hex, "eth_call", args, toBlockNumArg(blockNumber), s.overrides)
+	if err == nil {
+		return &Result{ReturnData: hex}, nil
+	}
+
+	res, ok := callFailure(err)
+	if !ok {
+		return nil, errors.WithMessage(err, "simulation: eth_call")
+	}
+	return res, nil
+}
+
+// callFailure returns the failed Result err stands for if err is the call
+// reverting or failing in the EVM rather than a node error.
+func callFailure(err error) (*Result, bool) {
+	if !rpcpool.IsExecutionError(err) {
+		return nil, false
+	}
+	res := &Result{Failed: true, RevertReason: err.Error()}
+	var de rpc.DataError
+	if errors.As(err, &de) {
+		if data, ok := de.ErrorData().(string); ok {
+			res.ReturnData, _ = hexutil.Decode(data)
+			if len(res.ReturnData) > 0 {
+				res.RevertReason = DecodeRevert(res.ReturnData)
+			}
+		}
+	}
+	return res, true
+}
+
+func toBlockNumArg(number *big.Int) string {
+	if number == nil {
+		return "latest"
+	}
+	pending := big.NewInt(-1)
+	if number.Cmp(pending) == 0 {
+		return "pending"
+	}
+	return hexutil.EncodeBig(number)
+}
+
+// ParseBlockNumber is the inverse of toBlockNumArg: "latest" (or empty) is
+// nil, "pending" is -1 and "earliest" is 0. Numbers may be hex or decimal.
+func ParseBlockNumber(tag string) (*big.Int, error) {
+	switch tag {
+	case "", "latest":
+		return nil, nil
+	case "pending":
+		return big.NewInt(-1), nil
+	case "earliest":
+		return new(big.Int), nil
+	}
+	if strings.HasPrefix(tag, "0x") {
+		number, err := hexutil.DecodeBig(tag)
+		if err != nil {
+			return nil, errors.WithMessagef(err, "simulation: block %q", tag)
+		}
+		return number, nil
+	}
+	number, ok := new(big.Int).SetString(tag, 10)
+	if !ok || number.Sign() < 0 {
+		return nil, errors.Errorf("simulation: invalid block %q", tag)
+	}
+	return number, nil
+}
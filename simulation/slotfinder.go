@@ -0,0 +1,134 @@
+package simulation
+
+import (
+	"context"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/pkg/errors"
+	"math/big"
+)
+
+var (
+	// SlotSearchLimit is the number of slots FindSlots tries per layout.
+	SlotSearchLimit uint64 = 256
+
+	// slotProbeOwner and slotProbeSpender are the accounts whose balance and
+	// allowance the slot search writes.
+	slotProbeOwner   = common.HexToAddress("0x1111111111111111111111111111111111111104")
+	slotProbeSpender = common.HexToAddress("0x1111111111111111111111111111111111111105")
+	slotProbeValue   = common.HexToHash("0x5107f1ade5")
+
+	// eip1967Implementation is the EIP-1967 implementation slot,
+	// keccak256("eip1967.proxy.implementation") - 1.
+	eip1967Implementation = common.HexToHash("0x360894a13ba1a3210667c828492db98dca3e2076cc3735a920a3ca505d382bbc")
+
+	ErrSlotNotFound = errors.New("simulation: slot not found")
+)
+
+// slotBatchSize bounds the eth_calls sent in one batch.
+const slotBatchSize = 64
+
+// FindSlots searches the balance and allowance slots of token by overriding
+// candidate storage keys and checking which one balanceOf/allowance return.
+// Solidity and Vyper layouts are tried; packed or computed balances are not
+// found and leave the slot nil. Decimals, symbol and EIP-1967 proxy details
+// are filled in too.
+func (s *Simulator) FindSlots(ctx context.Context, token common.Address, blockNumber *big.Int) (*TokenLayout, error) {
+	var chainID hexutil.Uint64
+	if err := s.client.CallContext(ctx, &chainID, "eth_chainId"); err != nil {
+		return nil, errors.WithMessage(err, "simulation: get chain id")
+	}
+	layout := &TokenLayout{ChainID: uint64(chainID), Token: token}
+
+	metadata, err := NewMetadataCache(s).Token(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	layout.Symbol, layout.Decimals = metadata.Symbol, metadata.Decimals
+
+	implementation, err := s.StateReader().Slot(ctx, token, eip1967Implementation, blockNumber)
+	if err != nil {
+		return nil, err
+	}
+	if implementation != (common.Hash{}) {
+		layout.Proxy = &Proxy{Kind: "eip1967", Implementation: common.BytesToAddress(implementation.Bytes())}
+	}
+
+	balanceOf, _ := erc20Abi.Pack("balanceOf", slotProbeOwner)
+	for _, kind := range []Layout{LayoutSolidity, LayoutVyper} {
+		candidate := TokenLayout{Layout: kind}
+		slot, err := s.searchSlot(ctx, token, balanceOf, blockNumber, func(slot uint64) common.Hash {
+			candidate.BalanceSlot = &slot
+			key, _ := candidate.BalanceKey(slotProbeOwner)
+			return key
+		})
+		if err == ErrSlotNotFound {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		layout.Layout, layout.BalanceSlot = kind, &slot
+		break
+	}
+	if layout.Layout == "" {
+		return layout, nil
+	}
+
+	allowance, _ := erc20Abi.Pack("allowance", slotProbeOwner, slotProbeSpender)
+	candidate := TokenLayout{Layout: layout.Layout}
+	slot, err := s.searchSlot(ctx, token, allowance, blockNumber, func(slot uint64) common.Hash {
+		candidate.AllowanceSlot = &slot
+		key, _ := candidate.AllowanceKey(slotProbeOwner, slotProbeSpender)
+		return key
+	})
+	switch err {
+	case nil:
+		layout.AllowanceSlot = &slot
+	case ErrSlotNotFound:
+	default:
+		return nil, err
+	}
+	return layout, nil
+}
+
+// FindAndRegister runs FindSlots and puts the result into registry. The
+// caller saves the registry.
+func (s *Simulator) FindAndRegister(ctx context.Context, registry *Registry, token common.Address, blockNumber *big.Int) (*TokenLayout, error) {
+	layout, err := s.FindSlots(ctx, token, blockNumber)
+	if err != nil {
+		return nil, err
+	}
+	registry.Put(*layout)
+	return layout, nil
+}
+
+// searchSlot sets the key of each candidate slot to slotProbeValue in turn
+// and returns the first slot for which input returns it.
+func (s *Simulator) searchSlot(ctx context.Context, token common.Address, input hexutil.Bytes, blockNumber *big.Int, keyOf func(slot uint64) common.Hash) (uint64, error) {
+	block := toBlockNumArg(blockNumber)
+	for start := uint64(0); start < SlotSearchLimit; start += slotBatchSize {
+		batch := make([]rpc.BatchElem, 0, slotBatchSize)
+		results := make([]hexutil.Bytes, slotBatchSize)
+		for slot := start; slot < start+slotBatchSize && slot < SlotSearchLimit; slot++ {
+			overrides := s.overrides.Merge(OverrideAccounts{
+				token: {StateDiff: map[string]string{keyOf(slot).Hex(): slotProbeValue.Hex()}},
+			})
+			batch = append(batch, rpc.BatchElem{
+				Method: "eth_call",
+				Args:   []interface{}{CallArgs{To: &token, Data: &input}, block, overrides},
+				Result: &results[slot-start],
+			})
+		}
+		if err := s.client.BatchCallContext(ctx, batch); err != nil {
+			return 0, errors.WithMessage(err, "simulation: search slot")
+		}
+		for i, elem := range batch {
+			if elem.Error == nil && common.BytesToHash(results[i]) == slotProbeValue {
+				return start + uint64(i), nil
+			}
+		}
+	}
+	return 0, ErrSlotNotFound
+}
@@ -0,0 +1,116 @@
+package simulation
+
+import (
+	"context"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/pkg/errors"
+	"math/big"
+)
+
+// StorageKey is a storage slot of a contract.
+type StorageKey struct {
+	Address common.Address
+	Key     common.Hash
+}
+
+// StateReader reads raw contract storage. Reads of several slots go out as a
+// single JSON-RPC batch.
+type StateReader struct {
+	client Client
+}
+
+func NewStateReader(client Client) *StateReader {
+	return &StateReader{client: client}
+}
+
+// StateReader returns a reader sharing the simulator's connection. It reads
+// the chain state; the simulator's overrides do not apply.
+func (s *Simulator) StateReader() *StateReader {
+	return NewStateReader(s.client)
+}
+
+// Slot reads a single storage slot at blockNumber, nil meaning latest.
+func (r *StateReader) Slot(ctx context.Context, addr common.Address, key common.Hash, blockNumber *big.Int) (common.Hash, error) {
+	values, err := r.Slots(ctx, []StorageKey{{addr, key}}, blockNumber)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return values[0], nil
+}
+
+// Slots reads keys, possibly of different contracts, in one round trip.
+func (r *StateReader) Slots(ctx context.Context, keys []StorageKey, blockNumber *big.Int) ([]common.Hash, error) {
+	results := make([]hexutil.Bytes, len(keys))
+	batch := make([]rpc.BatchElem, len(keys))
+	for i, key := range keys {
+		batch[i] = rpc.BatchElem{
+			Method: "eth_getStorageAt",
+			Args:   []interface{}{key.Address, key.Key, toBlockNumArg(blockNumber)},
+			Result: &results[i],
+		}
+	}
+	if err := r.client.BatchCallContext(ctx, batch); err != nil {
+		return nil, errors.WithMessage(err, "simulation: get storage")
+	}
+	values := make([]common.Hash, len(keys))
+	for i, elem := range batch {
+		if elem.Error != nil {
+			return nil, errors.WithMessagef(elem.Error, "simulation: get storage %s at %s", keys[i].Key, keys[i].Address)
+		}
+		values[i] = common.BytesToHash(results[i])
+	}
+	return values, nil
+}
+
+// BalanceOfSlot reads owner's balance from the balances mapping of token
+// declared at slot.
+func (r *StateReader) BalanceOfSlot(ctx context.Context, token, owner common.Address, slot *big.Int, blockNumber *big.Int) (*big.Int, error) {
+	value, err := r.Slot(ctx, token, BalanceKey(owner, slot), blockNumber)
+	if err != nil {
+		return nil, err
+	}
+	return value.Big(), nil
+}
+
+// BalancesOfSlot is BalanceOfSlot for many owners in one round trip.
+func (r *StateReader) BalancesOfSlot(ctx context.Context, token common.Address, owners []common.Address, slot *big.Int, blockNumber *big.Int) ([]*big.Int, error) {
+	keys := make([]StorageKey, len(owners))
+	for i, owner := range owners {
+		keys[i] = StorageKey{token, BalanceKey(owner, slot)}
+	}
+	values, err := r.Slots(ctx, keys, blockNumber)
+	if err != nil {
+		return nil, err
+	}
+	balances := make([]*big.Int, len(values))
+	for i, value := range values {
+		balances[i] = value.Big()
+	}
+	return balances, nil
+}
+
+// AllowanceSlot reads allowance[owner][spender] from the allowances mapping of
+// token declared at slot.
+func (r *StateReader) AllowanceSlot(ctx context.Context, token, owner, spender common.Address, slot *big.Int, blockNumber *big.Int) (*big.Int, error) {
+	value, err := r.Slot(ctx, token, AllowanceKey(owner, spender, slot), blockNumber)
+	if err != nil {
+		return nil, err
+	}
+	return value.Big(), nil
+}
+
+// BalanceKey is the storage key of owner in a Solidity mapping(address =>
+// uint) declared at slot.
+func BalanceKey(owner common.Address, slot *big.Int) common.Hash {
+	return crypto.Keccak256Hash(common.LeftPadBytes(owner.Bytes(), 32), common.BigToHash(slot).Bytes())
+}
+
+// AllowanceKey is the storage key of [owner][spender] in a Solidity
+// mapping(address => mapping(address => uint)) declared at slot.
+func AllowanceKey(owner, spender common.Address, slot *big.Int) common.Hash {
+	inner := BalanceKey(owner, slot)
+	return crypto.Keccak256Hash(common.LeftPadBytes(spender.Bytes(), 32), inner.Bytes())
+}
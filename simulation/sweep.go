@@ -0,0 +1,173 @@
+package simulation
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"geth/aggregator"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/pkg/errors"
+	"io"
+	"math/big"
+	"sort"
+	"strconv"
+)
+
+// SweepRequest is an aggregation router swap to simulate at each of Amounts.
+// Per amount, the calldata is rescaled with aggregator.SwapCall.Rescale, the
+// minimum return is dropped to 1 and From is funded with exactly the amount
+// through Registry. Swaps whose first hop is on a dex without a known amount
+// field fail.
+type SweepRequest struct {
+	Swap     SwapRequest
+	Amounts  []*big.Int
+	Registry *Registry
+	ChainID  uint64
+	// Workers is the number of amounts simulated at once.
+	Workers int
+}
+
+// SweepPoint is the swap at one amount. Price is AmountOut per AmountIn in
+// whole tokens; Impact is how much worse it is than at the smallest amount
+// that succeeded, 0.01 being 1%.
+type SweepPoint struct {
+	Amount *big.Int
+	Result *SwapResult
+	Err    error
+	Price  *big.Float
+	Impact *big.Float
+}
+
+// Sweep simulates req at every amount concurrently and returns the points by
+// increasing amount. Failing amounts are reported in their point.
+func (s *Simulator) Sweep(ctx context.Context, req SweepRequest, blockNumber *big.Int) ([]*SweepPoint, error) {
+	call, err := aggregator.DecodeSwapCall(req.Swap.Data)
+	if err != nil {
+		return nil, errors.WithMessage(err, "simulation: sweep")
+	}
+	tokens, err := NewMetadataCache(s).Get(ctx, call.Desc.SrcToken, call.Desc.DstToken)
+	if err != nil {
+		return nil, errors.WithMessage(err, "simulation: sweep metadata")
+	}
+	decimalsIn, decimalsOut := tokens[call.Desc.SrcToken].Decimals, tokens[call.Desc.DstToken].Decimals
+
+	amounts := make([]*big.Int, len(req.Amounts))
+	copy(amounts, req.Amounts)
+	sort.Slice(amounts, func(i, j int) bool { return amounts[i].Cmp(amounts[j]) < 0 })
+	points := make([]*SweepPoint, len(amounts))
+
+	pool := NewPool(ctx, s, PoolConfig{Workers: req.Workers})
+	go func() {
+		defer pool.Close()
+		for i, amount := range amounts {
+			i, amount := i, amount
+			job := Job{ID: strconv.Itoa(i), Run: func(ctx context.Context, sim *Simulator) (interface{}, error) {
+				res, err := sim.swapAt(ctx, req.Swap, req.Registry, req.ChainID, amount, big.NewInt(1), blockNumber)
+				if err == nil && !res.Success {
+					return nil, errors.Errorf("simulation: swap reverted: %s", res.RevertReason)
+				}
+				return res, err
+			}}
+			if pool.Submit(ctx, job) != nil {
+				return
+			}
+		}
+	}()
+	for result := range pool.Results() {
+		i, _ := strconv.Atoi(result.ID)
+		points[i] = &SweepPoint{Amount: amounts[i], Err: result.Err}
+		if result.Err == nil {
+			points[i].Result = result.Value.(*SwapResult)
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var base *big.Float
+	for _, point := range points {
+		if point.Err != nil || !point.Result.Success || point.Result.AmountIn.Sign() == 0 {
+			continue
+		}
+		point.Price = new(big.Float).Quo(units(point.Result.AmountOut, decimalsOut), units(point.Result.AmountIn, decimalsIn))
+		if base == nil {
+			base = point.Price
+		}
+		if base.Sign() > 0 {
+			// 1 - price / base
+			point.Impact = new(big.Float).Sub(big.NewFloat(1), new(big.Float).Quo(point.Price, base))
+		}
+	}
+	return points, nil
+}
+
+// swapAt runs swap rebuilt for amount and minReturn, with From funded with
+// exactly amount of swap.TokenIn through registry.
+func (s *Simulator) swapAt(ctx context.Context, swap SwapRequest, registry *Registry, chainID uint64, amount, minReturn *big.Int, blockNumber *big.Int) (*SwapResult, error) {
+	call, err := aggregator.DecodeSwapCall(swap.Data)
+	if err != nil {
+		return nil, err
+	}
+	if err := call.Rescale(amount, minReturn); err != nil {
+		return nil, err
+	}
+	data, err := call.Pack()
+	if err != nil {
+		return nil, err
+	}
+	var funding OverrideAccounts
+	if swap.TokenIn == aggregator.NativeToken {
+		funding = OverrideAccounts{swap.From: {Balance: hexutil.EncodeBig(amount)}}
+	} else {
+		if registry == nil {
+			return nil, ErrTokenNotRegistered
+		}
+		balance, err := registry.FundOverrides(chainID, swap.TokenIn, swap.From, amount)
+		if err != nil {
+			return nil, err
+		}
+		allowance, err := registry.ApproveOverrides(chainID, swap.TokenIn, swap.From, SimSwapAddress, amount)
+		if err != nil {
+			return nil, err
+		}
+		funding = balance.Merge(allowance)
+	}
+
+	swap.Data = data
+	swap.Value = call.Value()
+	// Token analysis would run once per amount; callers are after prices.
+	swap.BalanceSlots = nil
+	return s.WithOverrides(funding).SimulateSwap(ctx, swap, blockNumber)
+}
+
+// units is amount divided by 10^decimals.
+func units(amount *big.Int, decimals uint8) *big.Float {
+	unit := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil)
+	return new(big.Float).Quo(new(big.Float).SetInt(amount), new(big.Float).SetInt(unit))
+}
+
+// WriteSweepCSV writes points with amounts in whole tokens, as the columns
+// amountIn, token1Diff, token2Diff, gasUsed, price, impact and error.
+func WriteSweepCSV(w io.Writer, points []*SweepPoint, tokenIn, tokenOut *TokenMetadata) error {
+	writer := csv.NewWriter(w)
+	writer.Write([]string{"amountIn", "token1Diff", "token2Diff", "gasUsed", "price", "impact", "error"})
+	for _, point := range points {
+		row := []string{FormatUnits(point.Amount, tokenIn.Decimals), "", "", "", "", "", ""}
+		if point.Err != nil {
+			row[6] = point.Err.Error()
+		} else {
+			row[1] = FormatUnits(point.Result.AmountIn, tokenIn.Decimals)
+			row[2] = FormatUnits(point.Result.AmountOut, tokenOut.Decimals)
+			row[3] = fmt.Sprint(point.Result.GasUsed)
+		}
+		if point.Price != nil {
+			row[4] = point.Price.Text('g', 10)
+		}
+		if point.Impact != nil {
+			row[5] = point.Impact.Text('f', 6)
+		}
+		writer.Write(row)
+	}
+	writer.Flush()
+	return writer.Error()
+}
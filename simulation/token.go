@@ -0,0 +1,150 @@
+package simulation
+
+import (
+	"context"
+	"fmt"
+	"geth/contract/simswap"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/pkg/errors"
+	"math/big"
+)
+
+// TokenBehaviour classifies how a token handles transfers.
+type TokenBehaviour int
+
+const (
+	TokenStandard TokenBehaviour = iota
+	// TokenFeeOnTransfer tokens deliver less than the sender lost.
+	TokenFeeOnTransfer
+	// TokenRebasing tokens do not report the stored balance or move exactly
+	// the transferred amount, e.g. share based tokens like stETH.
+	TokenRebasing
+	// TokenBlocked tokens refused the transfer: paused, blacklisting or
+	// otherwise restricted.
+	TokenBlocked
+)
+
+func (b TokenBehaviour) String() string {
+	switch b {
+	case TokenStandard:
+		return "standard"
+	case TokenFeeOnTransfer:
+		return "fee-on-transfer"
+	case TokenRebasing:
+		return "rebasing"
+	case TokenBlocked:
+		return "blocked"
+	}
+	return fmt.Sprintf("TokenBehaviour(%d)", int(b))
+}
+
+// TransferRecipient receives the test transfer of AnalyzeToken.
+var TransferRecipient = common.HexToAddress("0x1111111111111111111111111111111111111101")
+
+// TokenReport is the outcome of a test transfer of Amount.
+type TokenReport struct {
+	Token     common.Address
+	Behaviour TokenBehaviour
+	Amount    *big.Int
+	// Sent and Received are the balance changes of the sender and the
+	// recipient.
+	Sent     *big.Int
+	Received *big.Int
+	// FeeBps is the share of Sent that did not arrive, in basis points.
+	FeeBps uint64
+	// Reason is the revert reason of a blocked transfer.
+	Reason string
+}
+
+func (r *TokenReport) String() string {
+	switch r.Behaviour {
+	case TokenBlocked:
+		return fmt.Sprintf("%s %s: %s", r.Token.Hex(), r.Behaviour, r.Reason)
+	case TokenFeeOnTransfer:
+		return fmt.Sprintf("%s %s, fee %d bps", r.Token.Hex(), r.Behaviour, r.FeeBps)
+	}
+	return fmt.Sprintf("%s %s", r.Token.Hex(), r.Behaviour)
+}
+
+// AdjustAmount returns what is left of amount after one transfer of the token.
+func (r *TokenReport) AdjustAmount(amount *big.Int) *big.Int {
+	if r.FeeBps >= 10000 {
+		return new(big.Int)
+	}
+	adjusted := new(big.Int).Mul(amount, new(big.Int).SetUint64(10000-r.FeeBps))
+	return adjusted.Div(adjusted, big.NewInt(10000))
+}
+
+// AnalyzeToken funds SimSwapAddress and TransferRecipient with amount of token
+// by overriding the balances mapping at balanceSlot (Solidity layout), then
+// has SimSwap transfer amount to the recipient and classifies the outcome.
+// Precedence is blocked, fee-on-transfer, rebasing, standard, except that a
+// recipient losing balance is rebasing.
+func (s *Simulator) AnalyzeToken(ctx context.Context, token common.Address, balanceSlot *big.Int, amount *big.Int, blockNumber *big.Int) (*TokenReport, error) {
+	data, err := simswapAbi.Pack("transfercheck", token, TransferRecipient, amount)
+	if err != nil {
+		return nil, errors.WithMessage(err, "simulation: pack transfercheck")
+	}
+	input := hexutil.Bytes(data)
+	args := CallArgs{
+		From: &TransferRecipient,
+		To:   &SimSwapAddress,
+		Data: &input,
+	}
+
+	funded := common.BigToHash(amount).Hex()
+	sim := s.WithOverrides(OverrideAccounts{
+		SimSwapAddress: {Code: simswap.RuntimeCode},
+		token: {StateDiff: map[string]string{
+			BalanceKey(SimSwapAddress, balanceSlot).Hex():    funded,
+			BalanceKey(TransferRecipient, balanceSlot).Hex(): funded,
+		}},
+	})
+	res, err := sim.Simulate(ctx, args, blockNumber)
+	if err != nil {
+		return nil, err
+	}
+	if res.Failed {
+		// balanceOf itself reverted.
+		return nil, errors.Errorf("simulation: transfercheck reverted: %s", res.RevertReason)
+	}
+	out, err := simswapAbi.Unpack("transfercheck", res.ReturnData)
+	if err != nil {
+		return nil, errors.WithMessage(err, "simulation: unpack transfercheck")
+	}
+	fromBefore, fromAfter := out[0].(*big.Int), out[1].(*big.Int)
+	toBefore, toAfter := out[2].(*big.Int), out[3].(*big.Int)
+	success, returnData := out[4].(bool), out[5].([]byte)
+
+	if fromBefore.Sign() == 0 {
+		return nil, errors.Errorf("simulation: slot %s does not hold the balances of %s", balanceSlot, token.Hex())
+	}
+	report := &TokenReport{
+		Token:    token,
+		Amount:   amount,
+		Sent:     new(big.Int).Sub(fromBefore, fromAfter),
+		Received: new(big.Int).Sub(toAfter, toBefore),
+	}
+	switch {
+	case !success:
+		report.Behaviour = TokenBlocked
+		report.Reason = DecodeRevert(returnData)
+		if len(returnData) == 32 {
+			report.Reason = "transfer returned false"
+		}
+	case report.Received.Sign() < 0:
+		// The recipient lost balance; no fee explains that.
+		report.Behaviour = TokenRebasing
+	case report.Received.Cmp(report.Sent) < 0 && report.Sent.Sign() > 0:
+		report.Behaviour = TokenFeeOnTransfer
+		fee := new(big.Int).Sub(report.Sent, report.Received)
+		report.FeeBps = fee.Mul(fee, big.NewInt(10000)).Div(fee, report.Sent).Uint64()
+	case fromBefore.Cmp(amount) != 0 || toBefore.Cmp(amount) != 0,
+		report.Sent.Cmp(amount) != 0 || report.Received.Cmp(amount) != 0:
+		report.Behaviour = TokenRebasing
+	default:
+		report.Behaviour = TokenStandard
+	}
+	return report, nil
+}
@@ -0,0 +1,177 @@
+package simulation
+
+import (
+	"context"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+	"math/big"
+)
+
+// refundQuotient is the EIP-3529 cap on gas refunds (gasUsed / 5).
+const refundQuotient = 5
+
+// logTracer is a JS tracer collecting the logs and ETH transfers a call would
+// make, dropping those of reverted frames, together with the numbers needed to derive the
+// gas a receipt would report.
+const logTracer = `{
+	logs: [[]],
+	transfers: [[]],
+	refund: 0,
+	step: function(log) {
+		this.refund = log.getRefund();
+		var op = log.op.toString();
+		if (op.indexOf("LOG") !== 0) {
+			return;
+		}
+		var offset = log.stack.peek(0).valueOf();
+		var end = offset + log.stack.peek(1).valueOf();
+		if (end > log.memory.length()) {
+			end = log.memory.length();
+		}
+		var topics = [];
+		for (var i = 0; i < parseInt(op.slice(3)); i++) {
+			topics.push("0x" + log.stack.peek(2 + i).toString(16));
+		}
+		this.logs[this.logs.length - 1].push({
+			address: toHex(log.contract.getAddress()),
+			topics: topics,
+			data: offset < end ? toHex(log.memory.slice(offset, end)) : "0x"
+		});
+	},
+	enter: function(frame) {
+		this.logs.push([]);
+		var transfers = [];
+		var type = frame.getType();
+		var value = frame.getValue();
+		if ((type === "CALL" || type.indexOf("CREATE") === 0 || type === "SELFDESTRUCT") && value !== undefined && !value.isZero()) {
+			transfers.push({from: toHex(frame.getFrom()), to: toHex(frame.getTo()), value: "0x" + value.toString(16)});
+		}
+		this.transfers.push(transfers);
+	},
+	exit: function(res) {
+		var logs = this.logs.pop();
+		var transfers = this.transfers.pop();
+		if (res.getError() === undefined) {
+			Array.prototype.push.apply(this.logs[this.logs.length - 1], logs);
+			Array.prototype.push.apply(this.transfers[this.transfers.length - 1], transfers);
+		}
+	},
+	fault: function(log) {},
+	result: function(ctx) {
+		if (ctx.error === undefined && !ctx.value.isZero()) {
+			this.transfers[0].unshift({from: toHex(ctx.from), to: toHex(ctx.to), value: "0x" + ctx.value.toString(16)});
+		}
+		return {
+			failed: ctx.error !== undefined,
+			error: ctx.error,
+			output: toHex(ctx.output),
+			intrinsicGas: ctx.intrinsicGas,
+			gasUsed: ctx.gasUsed,
+			refund: this.refund,
+			logs: ctx.error === undefined ? this.logs[0] : [],
+			transfers: ctx.error === undefined ? this.transfers[0] : []
+		};
+	}
+}`
+
+// BlockOverrides are the block header fields debug_traceCall can replace.
+type BlockOverrides struct {
+	Number     *hexutil.Big    `json:"number,omitempty"`
+	Difficulty *hexutil.Big    `json:"difficulty,omitempty"`
+	Time       *hexutil.Big    `json:"time,omitempty"`
+	GasLimit   *hexutil.Uint64 `json:"gasLimit,omitempty"`
+	Coinbase   *common.Address `json:"coinbase,omitempty"`
+	Random     *common.Hash    `json:"random,omitempty"`
+}
+
+type TraceConfig struct {
+	Tracer         string           `json:"tracer"`
+	Timeout        string           `json:"timeout,omitempty"`
+	StateOverrides OverrideAccounts `json:"stateOverrides,omitempty"`
+	BlockOverrides *BlockOverrides  `json:"blockOverrides,omitempty"`
+}
+
+// TraceResult is the outcome of a call executed through debug_traceCall.
+type TraceResult struct {
+	Failed     bool
+	Error      string
+	ReturnData []byte
+	// GasUsed is the gas a receipt would report: intrinsic plus execution
+	// gas, minus the capped refund.
+	GasUsed uint64
+	// Refund is the capped refund taken off GasUsed.
+	Refund uint64
+	Logs   []*types.Log
+	// Transfers are the ETH value transfers, the call's own value first.
+	Transfers []NativeTransfer
+}
+
+// NativeTransfer is ETH moved by a call, create or selfdestruct.
+type NativeTransfer struct {
+	From  common.Address `json:"from"`
+	To    common.Address `json:"to"`
+	Value *hexutil.Big   `json:"value"`
+}
+
+type traceLog struct {
+	Address common.Address `json:"address"`
+	Topics  []string       `json:"topics"`
+	Data    hexutil.Bytes  `json:"data"`
+}
+
+type traceOutput struct {
+	Failed       bool             `json:"failed"`
+	Error        string           `json:"error"`
+	Output       hexutil.Bytes    `json:"output"`
+	IntrinsicGas uint64           `json:"intrinsicGas"`
+	GasUsed      uint64           `json:"gasUsed"`
+	Refund       uint64           `json:"refund"`
+	Logs         []traceLog       `json:"logs"`
+	Transfers    []NativeTransfer `json:"transfers"`
+}
+
+// Trace executes args at blockNumber (nil means latest) via debug_traceCall,
+// returning the emitted logs and gas used, which eth_call cannot report.
+// blockOverrides may be nil.
+func (s *Simulator) Trace(ctx context.Context, args CallArgs, blockNumber *big.Int, blockOverrides *BlockOverrides) (*TraceResult, error) {
+	config := TraceConfig{
+		Tracer:         logTracer,
+		Timeout:        "20s",
+		StateOverrides: s.overrides,
+		BlockOverrides: blockOverrides,
+	}
+	var out traceOutput
+	if err := s.client.CallContext(ctx, &out, "debug_traceCall", args, toBlockNumArg(blockNumber), config); err != nil {
+		return nil, errors.WithMessage(err, "simulation: debug_traceCall")
+	}
+
+	gasUsed := out.IntrinsicGas + out.GasUsed
+	refund := out.Refund
+	if refund > gasUsed/refundQuotient {
+		refund = gasUsed / refundQuotient
+	}
+	res := &TraceResult{
+		Failed:     out.Failed,
+		Error:      out.Error,
+		ReturnData: out.Output,
+		GasUsed:    gasUsed - refund,
+		Refund:     refund,
+		Logs:       make([]*types.Log, 0, len(out.Logs)),
+		Transfers:  out.Transfers,
+	}
+	for i, l := range out.Logs {
+		topics := make([]common.Hash, 0, len(l.Topics))
+		for _, topic := range l.Topics {
+			topics = append(topics, common.HexToHash(topic))
+		}
+		res.Logs = append(res.Logs, &types.Log{
+			Address: l.Address,
+			Topics:  topics,
+			Data:    l.Data,
+			Index:   uint(i),
+		})
+	}
+	return res, nil
+}
@@ -0,0 +1,129 @@
+package simulation
+
+import (
+	"context"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/pkg/errors"
+	"math/big"
+)
+
+// SlotCheck names the slots to verify for a token. A nil slot is skipped.
+type SlotCheck struct {
+	Token         common.Address
+	Owner         common.Address
+	Spender       common.Address
+	BalanceSlot   *big.Int
+	AllowanceSlot *big.Int
+}
+
+// SlotReport compares the raw storage values of a SlotCheck with what
+// balanceOf and allowance return at the same block.
+type SlotReport struct {
+	Check       SlotCheck
+	BlockNumber *big.Int
+
+	BalanceFromSlot   *big.Int
+	BalanceOf         *big.Int
+	AllowanceFromSlot *big.Int
+	Allowance         *big.Int
+	// Errors holds failed balanceOf/allowance calls and storage reads.
+	Errors []string
+}
+
+// Mismatches describes the values that differ. A wrong slot constant reads
+// zero; proxies with another storage layout, packed balances and share based
+// tokens read something else.
+func (r *SlotReport) Mismatches() []string {
+	var mismatches []string
+	if r.BalanceFromSlot != nil && r.BalanceOf != nil && r.BalanceFromSlot.Cmp(r.BalanceOf) != 0 {
+		mismatches = append(mismatches, fmt.Sprintf("balance slot %s: storage %s, balanceOf %s", r.Check.BalanceSlot, r.BalanceFromSlot, r.BalanceOf))
+	}
+	if r.AllowanceFromSlot != nil && r.Allowance != nil && r.AllowanceFromSlot.Cmp(r.Allowance) != 0 {
+		mismatches = append(mismatches, fmt.Sprintf("allowance slot %s: storage %s, allowance %s", r.Check.AllowanceSlot, r.AllowanceFromSlot, r.Allowance))
+	}
+	return append(mismatches, r.Errors...)
+}
+
+func (r *SlotReport) OK() bool {
+	return len(r.Mismatches()) == 0
+}
+
+// Inconclusive reports whether every compared value is zero, in which case
+// a wrong slot would have matched too. Check with an account holding a
+// balance and an allowance.
+func (r *SlotReport) Inconclusive() bool {
+	for _, v := range []*big.Int{r.BalanceOf, r.Allowance} {
+		if v != nil && v.Sign() != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// VerifySlots reads the slots of every check and calls balanceOf/allowance,
+// all in one batch pinned to the same block. A nil blockNumber means the
+// latest block, resolved once beforehand.
+func (r *StateReader) VerifySlots(ctx context.Context, checks []SlotCheck, blockNumber *big.Int) ([]*SlotReport, error) {
+	if blockNumber == nil {
+		var latest hexutil.Big
+		if err := r.client.CallContext(ctx, &latest, "eth_blockNumber"); err != nil {
+			return nil, errors.WithMessage(err, "simulation: get block number")
+		}
+		blockNumber = latest.ToInt()
+	}
+	block := toBlockNumArg(blockNumber)
+
+	// results[i] is the output of batch[i], to be stored in targets[i] of
+	// owners[i].
+	var (
+		batch   []rpc.BatchElem
+		results []*hexutil.Bytes
+		targets []**big.Int
+		owners  []*SlotReport
+	)
+	add := func(report *SlotReport, target **big.Int, method string, args ...interface{}) {
+		result := new(hexutil.Bytes)
+		batch = append(batch, rpc.BatchElem{Method: method, Args: args, Result: result})
+		results = append(results, result)
+		targets = append(targets, target)
+		owners = append(owners, report)
+	}
+	call := func(token common.Address, method string, params ...interface{}) CallArgs {
+		data, _ := erc20Abi.Pack(method, params...)
+		input := hexutil.Bytes(data)
+		return CallArgs{To: &token, Data: &input}
+	}
+
+	reports := make([]*SlotReport, len(checks))
+	for i, check := range checks {
+		report := &SlotReport{Check: check, BlockNumber: blockNumber}
+		reports[i] = report
+		if check.BalanceSlot != nil {
+			add(report, &report.BalanceFromSlot, "eth_getStorageAt", check.Token, BalanceKey(check.Owner, check.BalanceSlot), block)
+			add(report, &report.BalanceOf, "eth_call", call(check.Token, "balanceOf", check.Owner), block)
+		}
+		if check.AllowanceSlot != nil {
+			add(report, &report.AllowanceFromSlot, "eth_getStorageAt", check.Token, AllowanceKey(check.Owner, check.Spender, check.AllowanceSlot), block)
+			add(report, &report.Allowance, "eth_call", call(check.Token, "allowance", check.Owner, check.Spender), block)
+		}
+	}
+	if err := r.client.BatchCallContext(ctx, batch); err != nil {
+		return nil, errors.WithMessage(err, "simulation: verify slots")
+	}
+
+	for i, elem := range batch {
+		report, result := owners[i], *results[i]
+		switch {
+		case elem.Error != nil:
+			report.Errors = append(report.Errors, fmt.Sprintf("%s: %v", elem.Method, elem.Error))
+		case len(result) != 32:
+			report.Errors = append(report.Errors, fmt.Sprintf("%s returned %d bytes", elem.Method, len(result)))
+		default:
+			*targets[i] = new(big.Int).SetBytes(result)
+		}
+	}
+	return reports, nil
+}
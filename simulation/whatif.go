@@ -0,0 +1,173 @@
+package simulation
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"geth/aggregator"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/pkg/errors"
+	"math/big"
+)
+
+// Modification lists what to change in a transaction before re-simulating
+// it. Nil fields are left as they are. Amount, MinReturnAmount and
+// DstReceiver rewrite the SwapDescription of an aggregation router swap;
+// Amount also scales the first hops in the executor data (see
+// aggregator.SwapCall.Rescale).
+type Modification struct {
+	From            *common.Address
+	Amount          *big.Int
+	MinReturnAmount *big.Int
+	DstReceiver     *common.Address
+	GasPrice        *big.Int
+	Timestamp       *uint64
+}
+
+// OutcomeDiff is one row of a side-by-side comparison.
+type OutcomeDiff struct {
+	Field    string
+	Original string
+	Modified string
+}
+
+func (d OutcomeDiff) Changed() bool {
+	return d.Original != d.Modified
+}
+
+// WhatIfResult holds the simulations of a transaction before and after a
+// Modification.
+type WhatIfResult struct {
+	Original *TraceResult
+	Modified *TraceResult
+	Diffs    []OutcomeDiff
+}
+
+// WhatIf re-simulates the mined or pending transaction txHash twice, as is and
+// with mod applied, under the same state and block context. A changed sender
+// has to be funded by the simulator's overrides.
+func (s *Simulator) WhatIf(ctx context.Context, txHash common.Hash, mod Modification) (*WhatIfResult, error) {
+	env, err := s.loadTransaction(ctx, txHash)
+	if err != nil {
+		return nil, err
+	}
+	args, blockOverrides, err := applyModification(env.args, env.blockOverrides, mod)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &WhatIfResult{}
+	res.Original, err = env.sim.Trace(ctx, env.args, env.blockNumber, env.blockOverrides)
+	if err != nil {
+		return nil, err
+	}
+	res.Modified, err = env.sim.Trace(ctx, args, env.blockNumber, blockOverrides)
+	if err != nil {
+		return nil, err
+	}
+	res.Diffs = diffOutcomes(res.Original, res.Modified)
+	return res, nil
+}
+
+func applyModification(args CallArgs, blockOverrides *BlockOverrides, mod Modification) (CallArgs, *BlockOverrides, error) {
+	if mod.From != nil {
+		args.From = mod.From
+	}
+	if mod.GasPrice != nil {
+		args.GasPrice = (*hexutil.Big)(mod.GasPrice)
+		args.MaxFeePerGas, args.MaxPriorityFeePerGas = nil, nil
+	}
+	if mod.Timestamp != nil {
+		modified := BlockOverrides{}
+		if blockOverrides != nil {
+			modified = *blockOverrides
+		}
+		modified.Time = (*hexutil.Big)(new(big.Int).SetUint64(*mod.Timestamp))
+		blockOverrides = &modified
+	}
+	if mod.Amount == nil && mod.MinReturnAmount == nil && mod.DstReceiver == nil {
+		return args, blockOverrides, nil
+	}
+
+	if args.Data == nil {
+		return args, nil, errors.New("simulation: transaction has no calldata to modify")
+	}
+	data, value, err := modifySwapCalldata(*args.Data, mod)
+	if err != nil {
+		return args, nil, err
+	}
+	args.Data = &data
+	if value != nil {
+		args.Value = (*hexutil.Big)(value)
+	}
+	return args, blockOverrides, nil
+}
+
+// modifySwapCalldata rewrites the SwapDescription of swap/swapSimpleMode
+// calldata. It also returns the new call value when an ETH amount changed.
+func modifySwapCalldata(calldata []byte, mod Modification) (hexutil.Bytes, *big.Int, error) {
+	call, err := aggregator.DecodeSwapCall(calldata)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var value *big.Int
+	minReturn := call.Desc.MinReturnAmount
+	if mod.MinReturnAmount != nil {
+		minReturn = mod.MinReturnAmount
+	}
+	if mod.Amount != nil {
+		// Scale the source amounts and the first hops of the executor in the
+		// original proportions.
+		if err := call.Rescale(mod.Amount, minReturn); err != nil {
+			return nil, nil, err
+		}
+		if call.Desc.SrcToken == aggregator.NativeToken {
+			value = call.Value()
+		}
+	}
+	call.Desc.MinReturnAmount = minReturn
+	if mod.DstReceiver != nil {
+		call.Desc.DstReceiver = *mod.DstReceiver
+	}
+
+	data, err := call.Pack()
+	if err != nil {
+		return nil, nil, err
+	}
+	return data, value, nil
+}
+
+func diffOutcomes(original, modified *TraceResult) []OutcomeDiff {
+	diffs := []OutcomeDiff{
+		{"failed", fmt.Sprint(original.Failed), fmt.Sprint(modified.Failed)},
+		{"error", original.Error, modified.Error},
+		{"gasUsed", fmt.Sprint(original.GasUsed), fmt.Sprint(modified.GasUsed)},
+		{"returnData", hexutil.Encode(original.ReturnData), hexutil.Encode(modified.ReturnData)},
+		{"logs", fmt.Sprint(len(original.Logs)), fmt.Sprint(len(modified.Logs))},
+	}
+	for i := 0; i < len(original.Logs) || i < len(modified.Logs); i++ {
+		var o, m string
+		if i < len(original.Logs) {
+			o = describeLog(original.Logs[i].Address, original.Logs[i].Topics, original.Logs[i].Data)
+		}
+		if i < len(modified.Logs) {
+			m = describeLog(modified.Logs[i].Address, modified.Logs[i].Topics, modified.Logs[i].Data)
+		}
+		diffs = append(diffs, OutcomeDiff{fmt.Sprintf("log %d", i), o, m})
+	}
+	return diffs
+}
+
+func describeLog(address common.Address, topics []common.Hash, data []byte) string {
+	var b bytes.Buffer
+	b.WriteString(address.Hex())
+	for _, topic := range topics {
+		b.WriteString(" ")
+		b.WriteString(topic.Hex())
+	}
+	b.WriteString(" ")
+	b.WriteString(hexutil.Encode(data))
+	return b.String()
+}
@@ -483,25 +483,160 @@ pragma solidity >=0.8 < 0.9;
 
 contract SimSwap {
 
+    // Sentinel the aggregation router uses for native ETH.
+    address constant NATIVE = 0xEeeeeEeeeEeEeeEeEeEeeEEEeeeeEeeeeeeeEEeE;
+    // Fresh address the honeypot check sends tokens to.
+    address constant HOLDER = 0x1111111111111111111111111111111111111102;
+
+    // Router refunds and sells pay out ETH to this contract.
+    receive() external payable {}
+
     function approve(address token, address spender) public returns(uint) {
         IERC20 erc20Token = IERC20(token);
         erc20Token.approve(spender,2**255);
         return 0;
     }
 
-    function simswap(address tokenIn, address tokenOut, address router, bytes calldata data) external payable returns(uint token1Diff, uint token2Diff) {
-        IERC20 token1 = IERC20(tokenIn);
-        IERC20 token2 = IERC20(tokenOut);
+    function getbalance(address tokenIn) external payable returns(uint token1Diff) {
+        token1Diff = balanceOf(tokenIn, msg.sender);
+    }
+
+    function getallowance(address token, address router) external payable returns(uint token1Diff) {
+        token1Diff = IERC20(token).allowance(msg.sender, router);
+    }
 
-        uint beforeBalance1 = token1.balanceOf(msg.sender);
-        uint beforeBalance2 = token2.balanceOf(msg.sender);
-        this.approve(tokenIn, router);
-        Address.functionDelegateCall(router, data);
-        uint afterBalance1 = token1.balanceOf(msg.sender);
-        uint afterBalance2 = token2.balanceOf(msg.sender);
+    // simswap runs the router call on behalf of msg.sender and reports the
+    // balance changes of tokenIn and tokenOut, the gas the router call used and
+    // its raw return data. A failing router call does not revert: success is
+    // false and returnData holds the revert data.
+    function simswap(address tokenIn, address tokenOut, address router, bytes calldata data) external payable returns(uint token1Diff, uint token2Diff, uint gasUsed, bool success, bytes memory returnData) {
+        require(Address.isContract(router), "SimSwap: router is not a contract");
+
+        uint beforeBalance1 = balanceOf(tokenIn, msg.sender);
+        if (tokenIn == NATIVE) {
+            // msg.value has already left msg.sender when this runs.
+            beforeBalance1 += msg.value;
+        }
+        uint beforeBalance2 = balanceOf(tokenOut, msg.sender);
+        if (tokenIn != NATIVE) {
+            this.approve(tokenIn, router);
+        }
+        // A delegatecall keeps msg.value, so the router sees the ETH sent along.
+        uint gasBefore = gasleft();
+        (success, returnData) = router.delegatecall(data);
+        gasUsed = gasBefore - gasleft();
+        uint afterBalance1 = balanceOf(tokenIn, msg.sender);
+        uint afterBalance2 = balanceOf(tokenOut, msg.sender);
         token1Diff = beforeBalance1 - afterBalance1;
         token2Diff = afterBalance2 - beforeBalance2;
 
-        return (token1Diff, token2Diff);
+        return (token1Diff, token2Diff, gasUsed, success, returnData);
+    }
+
+    // simswapMulti is simswap for an arbitrary list of tokens: it returns the
+    // signed balance change of msg.sender for each of them, so leftovers and
+    // refunds of intermediary tokens show up too. Every listed token is
+    // approved to the router; tokens whose approve fails are skipped.
+    function simswapMulti(address[] calldata tokens, address router, bytes calldata data) external payable returns(int[] memory deltas, uint gasUsed, bool success, bytes memory returnData) {
+        require(Address.isContract(router), "SimSwap: router is not a contract");
+
+        uint[] memory beforeBalances = new uint[](tokens.length);
+        for (uint i = 0; i < tokens.length; i++) {
+            beforeBalances[i] = balanceOf(tokens[i], msg.sender);
+            if (tokens[i] == NATIVE) {
+                // msg.value has already left msg.sender when this runs.
+                beforeBalances[i] += msg.value;
+            } else {
+                // Low-level call: tokens like USDT do not return a bool.
+                (bool approved, ) = tokens[i].call(abi.encodeWithSelector(IERC20.approve.selector, router, 2**255));
+                approved;
+            }
+        }
+        uint gasBefore = gasleft();
+        (success, returnData) = router.delegatecall(data);
+        gasUsed = gasBefore - gasleft();
+        deltas = new int[](tokens.length);
+        for (uint i = 0; i < tokens.length; i++) {
+            deltas[i] = int(balanceOf(tokens[i], msg.sender)) - int(beforeBalances[i]);
+        }
+
+        return (deltas, gasUsed, success, returnData);
+    }
+
+    // transfercheck transfers amount of token from this contract to `to` and
+    // reports both balances around the transfer. The transfer is a low-level
+    // call so that reverting (paused, blacklisting) tokens are reported rather
+    // than reverting the whole check.
+    function transfercheck(address token, address to, uint amount) external returns(uint fromBefore, uint fromAfter, uint toBefore, uint toAfter, bool success, bytes memory returnData) {
+        fromBefore = balanceOf(token, address(this));
+        toBefore = balanceOf(token, to);
+        (success, returnData) = token.call(abi.encodeWithSelector(IERC20.transfer.selector, to, amount));
+        if (success && returnData.length > 0) {
+            // Tokens returning false instead of reverting.
+            success = abi.decode(returnData, (bool));
+        }
+        fromAfter = balanceOf(token, address(this));
+        toAfter = balanceOf(token, to);
+
+        return (fromBefore, fromAfter, toBefore, toAfter, success, returnData);
+    }
+
+    // honeypot buys token with msg.value through a Uniswap V2 router, sends 1%
+    // of it to a fresh address and sells the rest back. The expected amounts
+    // are the router quotes, so bought/buyExpected and sold/sellExpected give
+    // the taxes. A reverting sell is reported, not propagated.
+    function honeypot(address router, address token) external payable returns(uint bought, uint buyExpected, bool transferOk, bool sellOk, uint sold, uint sellExpected, bytes memory sellRevert) {
+        IUniswapV2Router02 r = IUniswapV2Router02(router);
+        address[] memory path = new address[](2);
+        path[0] = r.WETH();
+        path[1] = token;
+        buyExpected = r.getAmountsOut(msg.value, path)[1];
+        r.swapExactETHForTokensSupportingFeeOnTransferTokens{value: msg.value}(0, path, address(this), block.timestamp);
+        bought = IERC20(token).balanceOf(address(this));
+
+        transferOk = lowLevelCall(token, abi.encodeWithSelector(IERC20.transfer.selector, HOLDER, bought / 100));
+
+        uint balance = IERC20(token).balanceOf(address(this));
+        path[0] = token;
+        path[1] = r.WETH();
+        sellExpected = r.getAmountsOut(balance, path)[1];
+        lowLevelCall(token, abi.encodeWithSelector(IERC20.approve.selector, router, balance));
+        uint ethBefore = address(this).balance;
+        try r.swapExactTokensForETHSupportingFeeOnTransferTokens(balance, 0, path, address(this), block.timestamp) {
+            sellOk = true;
+            sold = address(this).balance - ethBefore;
+        } catch (bytes memory reason) {
+            sellRevert = reason;
+        }
+    }
+
+    // multicall runs read-only calls and returns every result, failed or not,
+    // so that many reads take a single eth_call. Each call gets at most
+    // gasPerCall so that a target burning all its gas does not starve the
+    // others.
+    function multicall(address[] calldata targets, bytes[] calldata data, uint gasPerCall) external view returns(bool[] memory success, bytes[] memory results) {
+        require(targets.length == data.length, "SimSwap: length mismatch");
+        success = new bool[](targets.length);
+        results = new bytes[](targets.length);
+        for (uint i = 0; i < targets.length; i++) {
+            (success[i], results[i]) = targets[i].staticcall{gas: gasPerCall}(data[i]);
+        }
+    }
+
+    // lowLevelCall calls an ERC20 method, accepting tokens that return
+    // nothing instead of a bool.
+    function lowLevelCall(address token, bytes memory data) internal returns(bool) {
+        (bool success, bytes memory returnData) = token.call(data);
+        if (success && returnData.length > 0) {
+            success = abi.decode(returnData, (bool));
+        }
+        return success;
+    }
+
+    function balanceOf(address token, address account) internal view returns(uint) {
+        if (token == NATIVE) {
+            return account.balance;
+        }
+        return IERC20(token).balanceOf(account);
     }
 }
\ No newline at end of file
@@ -0,0 +1,70 @@
+package uniswapv2
+
+import (
+	"math/big"
+)
+
+// FeeBps is the swap fee of Uniswap V2 pairs. Forks with another fee can use
+// the *WithFee variants.
+const FeeBps = 30
+
+var bpsBase = big.NewInt(10000)
+
+// GetAmountOut is UniswapV2Library.getAmountOut: the output for amountIn
+// against the given reserves, after the 0.3% fee. It returns zero for empty
+// reserves or input.
+func GetAmountOut(amountIn, reserveIn, reserveOut *big.Int) *big.Int {
+	return GetAmountOutWithFee(amountIn, reserveIn, reserveOut, FeeBps)
+}
+
+func GetAmountOutWithFee(amountIn, reserveIn, reserveOut *big.Int, feeBps int64) *big.Int {
+	if amountIn.Sign() <= 0 || reserveIn.Sign() <= 0 || reserveOut.Sign() <= 0 {
+		return new(big.Int)
+	}
+	amountInWithFee := new(big.Int).Mul(amountIn, big.NewInt(10000-feeBps))
+	numerator := new(big.Int).Mul(amountInWithFee, reserveOut)
+	denominator := new(big.Int).Mul(reserveIn, bpsBase)
+	denominator.Add(denominator, amountInWithFee)
+	return numerator.Div(numerator, denominator)
+}
+
+// GetAmountIn is UniswapV2Library.getAmountIn: the input needed to receive
+// amountOut. It returns nil when amountOut cannot be reached.
+func GetAmountIn(amountOut, reserveIn, reserveOut *big.Int) *big.Int {
+	return GetAmountInWithFee(amountOut, reserveIn, reserveOut, FeeBps)
+}
+
+func GetAmountInWithFee(amountOut, reserveIn, reserveOut *big.Int, feeBps int64) *big.Int {
+	if amountOut.Sign() <= 0 || reserveIn.Sign() <= 0 || amountOut.Cmp(reserveOut) >= 0 {
+		return nil
+	}
+	numerator := new(big.Int).Mul(reserveIn, amountOut)
+	numerator.Mul(numerator, bpsBase)
+	denominator := new(big.Int).Sub(reserveOut, amountOut)
+	denominator.Mul(denominator, big.NewInt(10000-feeBps))
+	amountIn := numerator.Div(numerator, denominator)
+	return amountIn.Add(amountIn, big.NewInt(1))
+}
+
+// Quote is UniswapV2Library.quote: amountA priced at the reserve ratio,
+// without fee or price impact.
+func Quote(amountA, reserveA, reserveB *big.Int) *big.Int {
+	if reserveA.Sign() <= 0 {
+		return new(big.Int)
+	}
+	amountB := new(big.Int).Mul(amountA, reserveB)
+	return amountB.Div(amountB, reserveA)
+}
+
+// DeviationBps is how far got is from expected, in basis points of expected;
+// negative when got is lower. Use it to check a simulated hop against
+// GetAmountOut: fee-on-transfer tokens and stale reserves show up as a
+// negative deviation.
+func DeviationBps(expected, got *big.Int) int64 {
+	if expected.Sign() == 0 {
+		return 0
+	}
+	diff := new(big.Int).Sub(got, expected)
+	diff.Mul(diff, bpsBase)
+	return diff.Quo(diff, expected).Int64()
+}
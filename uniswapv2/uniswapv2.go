@@ -0,0 +1,149 @@
+package uniswapv2
+
+import (
+	"bytes"
+	"context"
+	"geth/contract/uniswap_v2_factory"
+	"geth/contract/uniswap_v2_pair"
+	"geth/contract/uniswap_v2_router"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"math/big"
+)
+
+var ErrNoPair = errors.New("uniswapv2: pair does not exist")
+
+// Reserves are a pair's reserves in token0/token1 order.
+type Reserves struct {
+	Token0             common.Address
+	Token1             common.Address
+	Reserve0           *big.Int
+	Reserve1           *big.Int
+	BlockTimestampLast uint32
+}
+
+// Of returns the reserves of tokenIn and of the other token.
+func (r *Reserves) Of(tokenIn common.Address) (reserveIn, reserveOut *big.Int) {
+	if tokenIn == r.Token0 {
+		return r.Reserve0, r.Reserve1
+	}
+	return r.Reserve1, r.Reserve0
+}
+
+// Client reads a Uniswap V2 deployment (or a fork sharing its interface)
+// through its router and factory.
+type Client struct {
+	backend bind.ContractCaller
+	router  *uniswap_v2_router.ContractCaller
+	factory *uniswap_v2_factory.ContractCaller
+
+	Router  common.Address
+	Factory common.Address
+}
+
+// NewClient binds the router and looks up its factory.
+func NewClient(ctx context.Context, backend bind.ContractCaller, router common.Address) (*Client, error) {
+	r, err := uniswap_v2_router.NewContractCaller(router, backend)
+	if err != nil {
+		return nil, errors.WithMessage(err, "uniswapv2: bind router")
+	}
+	factory, err := r.Factory(&bind.CallOpts{Context: ctx})
+	if err != nil {
+		return nil, errors.WithMessage(err, "uniswapv2: get factory")
+	}
+	f, err := uniswap_v2_factory.NewContractCaller(factory, backend)
+	if err != nil {
+		return nil, errors.WithMessage(err, "uniswapv2: bind factory")
+	}
+	return &Client{
+		backend: backend,
+		router:  r,
+		factory: f,
+		Router:  router,
+		Factory: factory,
+	}, nil
+}
+
+// GetPair returns the pair of tokenA and tokenB, ErrNoPair when there is none.
+func (c *Client) GetPair(ctx context.Context, tokenA, tokenB common.Address, blockNumber *big.Int) (common.Address, error) {
+	pair, err := c.factory.GetPair(callOpts(ctx, blockNumber), tokenA, tokenB)
+	if err != nil {
+		return common.Address{}, errors.WithMessage(err, "uniswapv2: get pair")
+	}
+	if pair == (common.Address{}) {
+		return common.Address{}, ErrNoPair
+	}
+	return pair, nil
+}
+
+// GetAmountsOut asks the router for the amounts along path.
+func (c *Client) GetAmountsOut(ctx context.Context, amountIn *big.Int, path []common.Address, blockNumber *big.Int) ([]*big.Int, error) {
+	amounts, err := c.router.GetAmountsOut(callOpts(ctx, blockNumber), amountIn, path)
+	if err != nil {
+		return nil, errors.WithMessage(err, "uniswapv2: get amounts out")
+	}
+	return amounts, nil
+}
+
+// GetReserves reads the reserves of pair.
+func (c *Client) GetReserves(ctx context.Context, pair common.Address, blockNumber *big.Int) (*Reserves, error) {
+	p, err := uniswap_v2_pair.NewContractCaller(pair, c.backend)
+	if err != nil {
+		return nil, errors.WithMessage(err, "uniswapv2: bind pair")
+	}
+	opts := callOpts(ctx, blockNumber)
+	token0, err := p.Token0(opts)
+	if err != nil {
+		return nil, errors.WithMessage(err, "uniswapv2: get token0")
+	}
+	token1, err := p.Token1(opts)
+	if err != nil {
+		return nil, errors.WithMessage(err, "uniswapv2: get token1")
+	}
+	reserves, err := p.GetReserves(opts)
+	if err != nil {
+		return nil, errors.WithMessage(err, "uniswapv2: get reserves")
+	}
+	return &Reserves{
+		Token0:             token0,
+		Token1:             token1,
+		Reserve0:           reserves.Reserve0,
+		Reserve1:           reserves.Reserve1,
+		BlockTimestampLast: reserves.BlockTimestampLast,
+	}, nil
+}
+
+// QuoteLocal computes the amounts along path from the pair reserves with
+// GetAmountOut instead of asking the router.
+func (c *Client) QuoteLocal(ctx context.Context, amountIn *big.Int, path []common.Address, blockNumber *big.Int) ([]*big.Int, error) {
+	if len(path) < 2 {
+		return nil, errors.New("uniswapv2: path needs at least two tokens")
+	}
+	amounts := []*big.Int{amountIn}
+	for i := 0; i < len(path)-1; i++ {
+		pair, err := c.GetPair(ctx, path[i], path[i+1], blockNumber)
+		if err != nil {
+			return nil, err
+		}
+		reserves, err := c.GetReserves(ctx, pair, blockNumber)
+		if err != nil {
+			return nil, err
+		}
+		reserveIn, reserveOut := reserves.Of(path[i])
+		amounts = append(amounts, GetAmountOut(amounts[i], reserveIn, reserveOut))
+	}
+	return amounts, nil
+}
+
+// SortTokens orders two tokens the way pairs store them.
+func SortTokens(tokenA, tokenB common.Address) (token0, token1 common.Address) {
+	if bytes.Compare(tokenA.Bytes(), tokenB.Bytes()) < 0 {
+		return tokenA, tokenB
+	}
+	return tokenB, tokenA
+}
+
+func callOpts(ctx context.Context, blockNumber *big.Int) *bind.CallOpts {
+	return &bind.CallOpts{Context: ctx, BlockNumber: blockNumber}
+}